@@ -1,25 +1,38 @@
 package app
 
 import (
+	"context"
+
+	"github.com/juju/errors"
 	"github.com/urfave/cli"
 	"github.com/wallester/migrate/commander"
+	"github.com/wallester/migrate/driver"
 	"github.com/wallester/migrate/driver/postgres"
 	"github.com/wallester/migrate/flag"
 	"github.com/wallester/migrate/migrator"
 	"github.com/wallester/migrate/printer"
+	"github.com/wallester/migrate/version"
 )
 
 // New returns new cli.App instance
 func New() *cli.App {
+	return NewWithContext(context.Background())
+}
+
+// NewWithContext returns new cli.App instance whose migrations are interrupted when ctx is canceled
+func NewWithContext(ctx context.Context) *cli.App {
 	p := printer.New()
 	d := postgres.New()
 	m := migrator.New(d, p)
-	cmd := commander.New(m)
+	m.SetDriverFactory(func() driver.IDriver {
+		return postgres.New()
+	})
+	cmd := commander.NewWithContext(ctx, m)
 
 	app := cli.NewApp()
 	app.Name = "migrate"
 	app.Usage = "Command line tool for Postgres migrations"
-	app.Version = "1.0.2"
+	app.Version = version.Tool
 	app.Commands = []cli.Command{
 		{
 			Name:      "create",
@@ -39,8 +52,23 @@ func New() *cli.App {
 			Flags: []cli.Flag{
 				flag.Flags[flag.Path],
 				flag.Flags[flag.URL],
+				flag.Flags[flag.URLsFile],
+				flag.Flags[flag.Concurrency],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
+				flag.Flags[flag.Schemas],
+				flag.Flags[flag.SchemaPattern],
+				flag.Flags[flag.ContinueOnError],
 				flag.Flags[flag.Timeout],
 				flag.Flags[flag.TimeoutDuration],
+				flag.Flags[flag.RunTimeoutDuration],
+				flag.Flags[flag.LockTimeoutDuration],
+				flag.Flags[flag.MaxAttempts],
+				flag.Flags[flag.RetryDelayDuration],
+				flag.Flags[flag.NoValidate],
+				flag.Flags[flag.NoChecksum],
+				flag.Flags[flag.DryRun],
+				flag.Flags[flag.Quiet],
 				flag.Flags[flag.Verbose],
 			},
 		},
@@ -52,21 +80,203 @@ func New() *cli.App {
 			Flags: []cli.Flag{
 				flag.Flags[flag.Path],
 				flag.Flags[flag.URL],
+				flag.Flags[flag.URLsFile],
+				flag.Flags[flag.Concurrency],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
+				flag.Flags[flag.Schemas],
+				flag.Flags[flag.SchemaPattern],
+				flag.Flags[flag.ContinueOnError],
+				flag.Flags[flag.Timeout],
+				flag.Flags[flag.TimeoutDuration],
+				flag.Flags[flag.RunTimeoutDuration],
+				flag.Flags[flag.LockTimeoutDuration],
+				flag.Flags[flag.MaxAttempts],
+				flag.Flags[flag.RetryDelayDuration],
+				flag.Flags[flag.NoValidate],
+				flag.Flags[flag.DryRun],
+				flag.Flags[flag.Quiet],
+				flag.Flags[flag.Verbose],
+			},
+		},
+		{
+			Name:      "goto",
+			Usage:     "Apply down and up migrations to land exactly on <version>",
+			Action:    cmd.Goto,
+			ArgsUsage: "<version>",
+			Flags: []cli.Flag{
+				flag.Flags[flag.Path],
+				flag.Flags[flag.URL],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
+				flag.Flags[flag.Timeout],
+				flag.Flags[flag.TimeoutDuration],
+				flag.Flags[flag.RunTimeoutDuration],
+				flag.Flags[flag.LockTimeoutDuration],
+				flag.Flags[flag.MaxAttempts],
+				flag.Flags[flag.RetryDelayDuration],
+				flag.Flags[flag.NoChecksum],
+				flag.Flags[flag.DryRun],
+				flag.Flags[flag.Quiet],
+				flag.Flags[flag.Verbose],
+			},
+		},
+		{
+			Name:      "redo",
+			Usage:     "Apply down and up again the latest <n> migration(s), defaults to 1",
+			Action:    cmd.Redo,
+			ArgsUsage: "[n]",
+			Flags: []cli.Flag{
+				flag.Flags[flag.Path],
+				flag.Flags[flag.URL],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
+				flag.Flags[flag.Timeout],
+				flag.Flags[flag.TimeoutDuration],
+				flag.Flags[flag.RunTimeoutDuration],
+				flag.Flags[flag.LockTimeoutDuration],
+				flag.Flags[flag.MaxAttempts],
+				flag.Flags[flag.RetryDelayDuration],
+				flag.Flags[flag.DryRun],
+				flag.Flags[flag.Quiet],
+				flag.Flags[flag.Verbose],
+			},
+		},
+		{
+			Name:      "script",
+			Usage:     "Write a psql script that applies <n> or all up migrations",
+			Action:    cmd.Script,
+			ArgsUsage: "<n>",
+			Flags: []cli.Flag{
+				flag.Flags[flag.Path],
+				flag.Flags[flag.URL],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
+				flag.Flags[flag.Timeout],
+				flag.Flags[flag.TimeoutDuration],
+				flag.Flags[flag.NoChecksum],
+				flag.Flags[flag.Output],
+				flag.Flags[flag.Verbose],
+			},
+		},
+		{
+			Name:   "status",
+			Usage:  "Show applied, pending and orphaned migrations",
+			Action: cmd.Status,
+			Flags: []cli.Flag{
+				flag.Flags[flag.Path],
+				flag.Flags[flag.URL],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
+				flag.Flags[flag.Timeout],
+				flag.Flags[flag.TimeoutDuration],
+				flag.Flags[flag.Verbose],
+			},
+		},
+		{
+			Name:      "history",
+			Usage:     "Show up and down migrations recorded in the migration history",
+			ArgsUsage: "[<version>]",
+			Action:    cmd.History,
+			Flags: []cli.Flag{
+				flag.Flags[flag.Path],
+				flag.Flags[flag.URL],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
+				flag.Flags[flag.Since],
+				flag.Flags[flag.Until],
+				flag.Flags[flag.Timeout],
+				flag.Flags[flag.TimeoutDuration],
+				flag.Flags[flag.Verbose],
+			},
+		},
+		{
+			Name:   "lint",
+			Usage:  "Warn about risky SQL in migration files",
+			Action: cmd.Lint,
+			Flags: []cli.Flag{
+				flag.Flags[flag.Path],
+				flag.Flags[flag.LintDisable],
+			},
+		},
+		{
+			Name:   "validate",
+			Usage:  "Report duplicate, unpaired, unrecognised, stray, empty and out of order migration files",
+			Action: cmd.Validate,
+			Flags: []cli.Flag{
+				flag.Flags[flag.Path],
+				flag.Flags[flag.URL],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
 				flag.Flags[flag.Timeout],
 				flag.Flags[flag.TimeoutDuration],
 				flag.Flags[flag.Verbose],
 			},
 		},
+		{
+			Name:   "repair",
+			Usage:  "Update checksums of already applied migrations after an intentional change",
+			Action: cmd.Repair,
+			Flags: []cli.Flag{
+				flag.Flags[flag.Path],
+				flag.Flags[flag.URL],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
+				flag.Flags[flag.Timeout],
+				flag.Flags[flag.TimeoutDuration],
+				flag.Flags[flag.Verbose],
+			},
+		},
+		{
+			Name:      "force",
+			Usage:     "Mark <version> as applied (up) or not applied (down) without running it",
+			Action:    cmd.Force,
+			ArgsUsage: "<version> [up|down]",
+			Flags: []cli.Flag{
+				flag.Flags[flag.Path],
+				flag.Flags[flag.URL],
+				flag.Flags[flag.Table],
+				flag.Flags[flag.Schema],
+				flag.Flags[flag.Timeout],
+				flag.Flags[flag.TimeoutDuration],
+				flag.Flags[flag.LockTimeoutDuration],
+				flag.Flags[flag.Verbose],
+			},
+		},
 	}
 
 	app.Flags = []cli.Flag{
 		flag.Flags[flag.Path],
 		flag.Flags[flag.URL],
+		flag.Flags[flag.Table],
+		flag.Flags[flag.Schema],
 		flag.Flags[flag.Timeout],
 		flag.Flags[flag.TimeoutDuration],
+		flag.Flags[flag.RunTimeoutDuration],
+		flag.Flags[flag.LockTimeoutDuration],
 		flag.Flags[flag.NoVerify],
+		flag.Flags[flag.NoValidate],
+		flag.Flags[flag.NoChecksum],
+		flag.Flags[flag.Format],
+		flag.Flags[flag.ConfigFile],
+		flag.Flags[flag.Env],
 		flag.Flags[flag.Verbose],
 	}
 
+	app.Before = func(c *cli.Context) error {
+		if err := flag.LoadConfig(c); err != nil {
+			return errors.Annotate(err, "loading configuration failed")
+		}
+
+		output, err := printer.NewWithFormat(flag.Get(c, flag.Format))
+		if err != nil {
+			return flag.NewWrongFormatFlagError(flag.Format)
+		}
+
+		m.SetOutput(output)
+
+		return nil
+	}
+
 	return app
 }
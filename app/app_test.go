@@ -19,6 +19,12 @@ func Test_New_ReturnsInstance_InCaseOfSuccess(t *testing.T) {
 			assert.True(t, hasCommand("create", app.Commands))
 			assert.True(t, hasCommand("up", app.Commands))
 			assert.True(t, hasCommand("down", app.Commands))
+			assert.True(t, hasCommand("status", app.Commands))
+			assert.True(t, hasCommand("repair", app.Commands))
+			assert.True(t, hasCommand("force", app.Commands))
+			assert.True(t, hasCommand("goto", app.Commands))
+			assert.True(t, hasCommand("redo", app.Commands))
+			assert.True(t, hasCommand("script", app.Commands))
 		}
 
 		if assert.NotNil(t, app.Flags) {
@@ -26,6 +32,8 @@ func Test_New_ReturnsInstance_InCaseOfSuccess(t *testing.T) {
 			assert.True(t, hasFlag("url", app.Flags))
 			assert.True(t, hasFlag("timeout", app.Flags))
 			assert.True(t, hasFlag("no-verify", app.Flags))
+			assert.True(t, hasFlag("no-checksum", app.Flags))
+			assert.True(t, hasFlag("format", app.Flags))
 		}
 	}
 }
@@ -1,7 +1,11 @@
 package commander
 
 import (
+	"context"
+	"fmt"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
@@ -11,23 +15,46 @@ import (
 	"github.com/wallester/migrate/migrator"
 )
 
+const (
+	// ExitCodePendingMigrations is the exit code of dry runs that found migrations to apply
+	ExitCodePendingMigrations = 2
+	// ExitCodeInterrupted is the exit code of migrations interrupted by a signal
+	ExitCodeInterrupted = 130
+)
+
 // ICommander represents app commands
 type ICommander interface {
 	Create(c *cli.Context) error
 	Up(c *cli.Context) error
 	Down(c *cli.Context) error
+	Status(c *cli.Context) error
+	Repair(c *cli.Context) error
+	Force(c *cli.Context) error
+	Goto(c *cli.Context) error
+	Redo(c *cli.Context) error
+	Script(c *cli.Context) error
+	History(c *cli.Context) error
+	Lint(c *cli.Context) error
+	Validate(c *cli.Context) error
 }
 
 type Commander struct {
-	m migrator.IMigrator
+	ctx context.Context
+	m   migrator.IMigrator
 }
 
 var _ ICommander = (*Commander)(nil)
 
 // New returns new instance
 func New(m migrator.IMigrator) *Commander {
+	return NewWithContext(context.Background(), m)
+}
+
+// NewWithContext returns new instance whose migrations stop when ctx is canceled
+func NewWithContext(ctx context.Context, m migrator.IMigrator) *Commander {
 	return &Commander{
-		m: m,
+		ctx: ctx,
+		m:   m,
 	}
 }
 
@@ -54,14 +81,14 @@ func (cmd *Commander) Create(c *cli.Context) error {
 
 // Up migrates up
 func (cmd *Commander) Up(c *cli.Context) error {
-	args, err := parseMigrateArguments(c)
+	args, err := parseMultiTargetArguments(c)
 	if err != nil {
 		return errors.Annotate(err, "parsing parameters failed")
 	}
 
 	args.Direction = direction.Up
-	if err := cmd.m.Migrate(*args); err != nil {
-		return errors.Annotate(err, "migrating up failed")
+	if _, err := cmd.m.MigrateContext(cmd.ctx, *args); err != nil {
+		return newExitError(errors.Annotate(err, "migrating up failed"))
 	}
 
 	return nil
@@ -69,7 +96,7 @@ func (cmd *Commander) Up(c *cli.Context) error {
 
 // Down migrates down
 func (cmd *Commander) Down(c *cli.Context) error {
-	args, err := parseMigrateArguments(c)
+	args, err := parseMultiTargetArguments(c)
 	if err != nil {
 		return errors.Annotate(err, "parsing parameters failed")
 	}
@@ -79,8 +106,227 @@ func (cmd *Commander) Down(c *cli.Context) error {
 	}
 
 	args.Direction = direction.Down
-	if err := cmd.m.Migrate(*args); err != nil {
-		return errors.Annotate(err, "migrating down failed")
+	if _, err := cmd.m.MigrateContext(cmd.ctx, *args); err != nil {
+		return newExitError(errors.Annotate(err, "migrating down failed"))
+	}
+
+	return nil
+}
+
+// Status prints migration statuses
+func (cmd *Commander) Status(c *cli.Context) error {
+	args, err := parseMigrateArguments(c)
+	if err != nil {
+		return errors.Annotate(err, "parsing parameters failed")
+	}
+
+	statuses, err := cmd.m.Status(*args)
+	if err != nil {
+		return errors.Annotate(err, "getting migration status failed")
+	}
+
+	pending := statuses.Count(migrator.Pending)
+	orphaned := statuses.Count(migrator.Orphaned)
+	dirty := statuses.Count(migrator.Dirty)
+	if pending > 0 || orphaned > 0 || dirty > 0 {
+		return fmt.Errorf("found %d pending, %d orphaned and %d dirty migration(s)", pending, orphaned, dirty)
+	}
+
+	return nil
+}
+
+// Repair updates checksums of changed migration files
+func (cmd *Commander) Repair(c *cli.Context) error {
+	args, err := parseMigrateArguments(c)
+	if err != nil {
+		return errors.Annotate(err, "parsing parameters failed")
+	}
+
+	if _, err := cmd.m.Repair(*args); err != nil {
+		return errors.Annotate(err, "repairing migrations failed")
+	}
+
+	return nil
+}
+
+// Goto migrates up or down to the given version
+func (cmd *Commander) Goto(c *cli.Context) error {
+	args, err := parseMigrateArguments(c)
+	if err != nil {
+		return errors.Annotate(err, "parsing parameters failed")
+	}
+
+	v, err := parseVersionArgument(c)
+	if err != nil {
+		return errors.Annotate(err, "parsing parameters failed")
+	}
+
+	args.Version = v
+	args.Steps = 0
+
+	if err := cmd.m.GotoContext(cmd.ctx, *args); err != nil {
+		return newExitError(errors.Annotatef(err, "migrating to version %d failed", v))
+	}
+
+	return nil
+}
+
+// Redo migrates down and up again the latest <n> migrations
+func (cmd *Commander) Redo(c *cli.Context) error {
+	args, err := parseMigrateArguments(c)
+	if err != nil {
+		return errors.Annotate(err, "parsing parameters failed")
+	}
+
+	if args.Steps < 1 {
+		args.Steps = 1
+	}
+
+	if err := cmd.m.RedoContext(cmd.ctx, *args); err != nil {
+		return newExitError(errors.Annotate(err, "redoing migrations failed"))
+	}
+
+	return nil
+}
+
+// Script writes a SQL script of up migrations
+func (cmd *Commander) Script(c *cli.Context) error {
+	args, err := parseMigrateArguments(c)
+	if err != nil {
+		return errors.Annotate(err, "parsing parameters failed")
+	}
+
+	args.Output = flag.Get(c, flag.Output)
+
+	if _, err := cmd.m.ScriptContext(cmd.ctx, *args); err != nil {
+		return errors.Annotate(err, "writing migration script failed")
+	}
+
+	return nil
+}
+
+// Force marks a version as applied or not applied without executing it
+func (cmd *Commander) Force(c *cli.Context) error {
+	args, err := parseMigrateArguments(c)
+	if err != nil {
+		return errors.Annotate(err, "parsing parameters failed")
+	}
+
+	v, err := parseVersionArgument(c)
+	if err != nil {
+		return errors.Annotate(err, "parsing parameters failed")
+	}
+
+	args.Version = v
+	args.Steps = 0
+
+	switch c.Args().Get(1) {
+	case "", direction.Up.ToString():
+		args.Direction = direction.Up
+	case direction.Down.ToString():
+		args.Direction = direction.Down
+	default:
+		return flag.NewWrongFormatFlagError("<direction>")
+	}
+
+	if err := cmd.m.Force(*args); err != nil {
+		return errors.Annotate(err, "forcing version failed")
+	}
+
+	return nil
+}
+
+// Lint prints risky patterns found in the migration files
+func (cmd *Commander) Lint(c *cli.Context) error {
+	path := flag.Get(c, flag.Path)
+	if path == "" {
+		return flag.NewRequiredFlagError(flag.Path)
+	}
+
+	findings, err := cmd.m.Lint(migrator.Args{
+		Path:        path,
+		LintDisable: parseList(flag.Get(c, flag.LintDisable)),
+	})
+	if err != nil {
+		return errors.Annotate(err, "linting migrations failed")
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("found %d lint warning(s)", len(findings))
+	}
+
+	return nil
+}
+
+// Validate prints structural problems of the migration files,
+// given a database URL also pending migrations older than the latest applied migration
+func (cmd *Commander) Validate(c *cli.Context) error {
+	args := &migrator.Args{Path: flag.Get(c, flag.Path)}
+	if args.Path == "" {
+		return flag.NewRequiredFlagError(flag.Path)
+	}
+
+	if len(flag.GetStrings(c, flag.URL)) > 0 {
+		var err error
+		args, err = parseMigrateArguments(c)
+		if err != nil {
+			return errors.Annotate(err, "parsing parameters failed")
+		}
+	}
+
+	problems, err := cmd.m.Validate(*args)
+	if err != nil {
+		return errors.Annotate(err, "validating migrations failed")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problem(s) in migration files", len(problems))
+	}
+
+	return nil
+}
+
+// History prints the recorded up and down migrations
+func (cmd *Commander) History(c *cli.Context) error {
+	args, err := parseMigrateArguments(c)
+	if err != nil {
+		return errors.Annotate(err, "parsing parameters failed")
+	}
+
+	args.Steps = 0
+	if c.Args().First() != "" {
+		v, err := parseVersionArgument(c)
+		if err != nil {
+			return errors.Annotate(err, "parsing parameters failed")
+		}
+
+		args.Version = v
+	}
+
+	if s := flag.Get(c, flag.Since); s != "" {
+		since, _, err := parseTime(s)
+		if err != nil {
+			return flag.NewWrongFormatFlagError(flag.Since)
+		}
+
+		args.Since = since
+	}
+
+	if s := flag.Get(c, flag.Until); s != "" {
+		until, isDate, err := parseTime(s)
+		if err != nil {
+			return flag.NewWrongFormatFlagError(flag.Until)
+		}
+
+		if isDate {
+			until = until.AddDate(0, 0, 1)
+		}
+
+		args.Until = until
+	}
+
+	if _, err := cmd.m.History(*args); err != nil {
+		return errors.Annotate(err, "listing migration history failed")
 	}
 
 	return nil
@@ -89,16 +335,60 @@ func (cmd *Commander) Down(c *cli.Context) error {
 // private
 
 func parseMigrateArguments(c *cli.Context) (*migrator.Args, error) {
+	args, err := parseMultiTargetArguments(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args.URLs) > 0 {
+		return nil, errors.New("please specify a single " + flag.URL)
+	}
+
+	if len(args.Schemas) > 0 || args.SchemaPattern != "" {
+		return nil, errors.New("please specify " + flag.Schemas + " or " + flag.SchemaPattern + " only with up or down")
+	}
+
+	return args, nil
+}
+
+// parseMultiTargetArguments parses migrate arguments that may name multiple databases
+func parseMultiTargetArguments(c *cli.Context) (*migrator.Args, error) {
 	path := flag.Get(c, flag.Path)
 	if path == "" {
 		return nil, flag.NewRequiredFlagError(flag.Path)
 	}
 
-	url := flag.Get(c, flag.URL)
-	if url == "" {
+	urls := flag.GetStrings(c, flag.URL)
+	if file := flag.Get(c, flag.URLsFile); file != "" {
+		fileURLs, err := readURLsFile(file)
+		if err != nil {
+			return nil, errors.Annotate(err, "reading "+flag.URLsFile+" failed")
+		}
+
+		urls = append(urls, fileURLs...)
+	}
+
+	if len(urls) == 0 {
 		return nil, flag.NewRequiredFlagError(flag.URL)
 	}
 
+	url := urls[0]
+
+	var targets []string
+	concurrency := 0
+	if len(urls) > 1 {
+		targets = urls
+		concurrency = defaultConcurrency
+		if s := flag.Get(c, flag.Concurrency); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return nil, flag.NewWrongFormatFlagError(flag.Concurrency)
+			}
+
+			concurrency = n
+		}
+	}
+
 	timeoutDuration := time.Second
 	if s := flag.Get(c, flag.Timeout); s != "" {
 		timeoutSeconds, err := strconv.Atoi(s)
@@ -117,6 +407,34 @@ func parseMigrateArguments(c *cli.Context) (*migrator.Args, error) {
 		}
 	}
 
+	var runTimeoutDuration time.Duration
+	if s := flag.Get(c, flag.RunTimeoutDuration); s != "" {
+		var err error
+		runTimeoutDuration, err = time.ParseDuration(s)
+		if err != nil {
+			return nil, flag.NewWrongFormatFlagError(flag.RunTimeoutDuration)
+		}
+	}
+
+	var maxAttempts int
+	if s := flag.Get(c, flag.MaxAttempts); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return nil, flag.NewWrongFormatFlagError(flag.MaxAttempts)
+		}
+
+		maxAttempts = n
+	}
+
+	var retryDelayDuration time.Duration
+	if s := flag.Get(c, flag.RetryDelayDuration); s != "" {
+		var err error
+		retryDelayDuration, err = time.ParseDuration(s)
+		if err != nil {
+			return nil, flag.NewWrongFormatFlagError(flag.RetryDelayDuration)
+		}
+	}
+
 	dbConnectionTimeoutDuration := time.Second
 	if s := flag.Get(c, flag.DBConnectionTimeoutDuration); s != "" {
 		var err error
@@ -126,6 +444,15 @@ func parseMigrateArguments(c *cli.Context) (*migrator.Args, error) {
 		}
 	}
 
+	lockTimeoutDuration := time.Minute
+	if s := flag.Get(c, flag.LockTimeoutDuration); s != "" {
+		var err error
+		lockTimeoutDuration, err = time.ParseDuration(s)
+		if err != nil {
+			return nil, flag.NewWrongFormatFlagError(flag.LockTimeoutDuration)
+		}
+	}
+
 	var steps int
 	if s := c.Args().First(); s != "" {
 		n, err := strconv.Atoi(s)
@@ -136,16 +463,126 @@ func parseMigrateArguments(c *cli.Context) (*migrator.Args, error) {
 		steps = n
 	}
 
+	table := flag.Get(c, flag.Table)
+	schema := flag.Get(c, flag.Schema)
+	schemas := parseList(flag.Get(c, flag.Schemas))
+	schemaPattern := flag.Get(c, flag.SchemaPattern)
+	if len(schemas) > 0 && schemaPattern != "" {
+		return nil, errors.New("please specify either " + flag.Schemas + " or " + flag.SchemaPattern)
+	}
+
+	if schema != "" && (len(schemas) > 0 || schemaPattern != "") {
+		return nil, errors.New("please specify either " + flag.Schema + " or " + flag.Schemas)
+	}
+
+	continueOnError := flag.GetBool(c, flag.ContinueOnError)
+	dryRun := flag.GetBool(c, flag.DryRun)
+	quiet := flag.GetBool(c, flag.Quiet)
 	noVerify := flag.GetBool(c, flag.NoVerify)
+	noValidate := flag.GetBool(c, flag.NoValidate)
+	noChecksum := flag.GetBool(c, flag.NoChecksum)
 	verbose := flag.GetBool(c, flag.Verbose)
 
 	return &migrator.Args{
 		Path:                        path,
 		URL:                         url,
+		URLs:                        targets,
+		Concurrency:                 concurrency,
+		Table:                       table,
+		Schema:                      schema,
+		Schemas:                     schemas,
+		SchemaPattern:               schemaPattern,
+		ContinueOnError:             continueOnError,
 		Steps:                       steps,
 		NoVerify:                    noVerify,
+		NoValidate:                  noValidate,
+		NoChecksum:                  noChecksum,
 		TimeoutDuration:             timeoutDuration,
+		RunTimeoutDuration:          runTimeoutDuration,
+		MaxAttempts:                 maxAttempts,
+		RetryDelayDuration:          retryDelayDuration,
 		DBConnectionTimeoutDuration: dbConnectionTimeoutDuration,
+		LockTimeoutDuration:         lockTimeoutDuration,
+		DryRun:                      dryRun,
+		Quiet:                       quiet,
 		Verbose:                     verbose,
 	}, nil
 }
+
+// defaultConcurrency is the number of databases migrated at the same time if not given
+const defaultConcurrency = 4
+
+// readURLsFile reads one database URL per line, ignoring empty lines and # comments
+func readURLsFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Annotate(err, "reading file failed")
+	}
+
+	var urls []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		urls = append(urls, line)
+	}
+
+	return urls, nil
+}
+
+// parseList splits a comma separated list, ignoring empty items
+func parseList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func parseVersionArgument(c *cli.Context) (int64, error) {
+	s := c.Args().First()
+	if s == "" {
+		return 0, flag.NewRequiredFlagError("<version>")
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, flag.NewWrongFormatFlagError("<version>")
+	}
+
+	return v, nil
+}
+
+// parseTime parses an RFC 3339 time or a date and reports whether it was a date.
+// Times are converted to UTC, because the history is recorded in UTC.
+func parseTime(s string) (time.Time, bool, error) {
+	if t, err := time.Parse(dateFormat, s); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return t.UTC(), false, nil
+}
+
+const dateFormat = "2006-01-02"
+
+// newExitError returns an error with a distinct exit code if the migrator reported one
+func newExitError(err error) error {
+	switch errors.Cause(err) {
+	case migrator.ErrPendingMigrations:
+		return cli.NewExitError(err.Error(), ExitCodePendingMigrations)
+	case migrator.ErrInterrupted:
+		return cli.NewExitError(err.Error(), ExitCodeInterrupted)
+	}
+
+	return err
+}
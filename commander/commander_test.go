@@ -1,7 +1,9 @@
 package commander
 
 import (
+	"context"
 	"flag"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,6 +12,8 @@ import (
 	"github.com/urfave/cli"
 	"github.com/wallester/migrate/direction"
 	"github.com/wallester/migrate/file"
+	migrateflag "github.com/wallester/migrate/flag"
+	"github.com/wallester/migrate/lint"
 	"github.com/wallester/migrate/migrator"
 )
 
@@ -30,6 +34,24 @@ func (suite *CommanderTestSuite) SetupTest() {
 	suite.ctx = cli.NewContext(nil, suite.flagSet, nil)
 }
 
+// newApp returns an app with the up command and the URL flag defined globally and by the command
+func (suite *CommanderTestSuite) newApp() *cli.App {
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{migrateflag.Flags[migrateflag.URL]}
+	app.Commands = []cli.Command{
+		{
+			Name:   "up",
+			Action: suite.commander.Up,
+			Flags: []cli.Flag{
+				migrateflag.Flags[migrateflag.Path],
+				migrateflag.Flags[migrateflag.URL],
+			},
+		},
+	}
+
+	return app
+}
+
 func Test_Commander_TestSuite(t *testing.T) {
 	suite.Run(t, &CommanderTestSuite{})
 }
@@ -135,9 +157,10 @@ func (suite *CommanderTestSuite) Test_Up_ReturnsError_InCaseOfMigratorError() {
 		Direction:                   direction.Up,
 		TimeoutDuration:             10 * time.Second,
 		DBConnectionTimeoutDuration: 10 * time.Second,
+		LockTimeoutDuration:         time.Minute,
 	}
 
-	suite.migratorMock.On("Migrate", args).Return(suite.expectedErr).Once()
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, suite.expectedErr).Once()
 
 	// Act
 	err := suite.commander.Up(suite.ctx)
@@ -181,9 +204,149 @@ func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfSuccessAndTimeoutDur
 		Steps:                       0,
 		TimeoutDuration:             10 * time.Second,
 		DBConnectionTimeoutDuration: 10 * time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.commander.Up(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Up_UsesGlobalURL_InCaseOfURLEnvironmentVariable() {
+	// Arrange
+	suite.T().Setenv(migrateflag.URLEnvVar, "postgres://localhost:1/envdb")
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "postgres://localhost:2/flagdb",
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.newApp().Run([]string{"migrate", "--url", "postgres://localhost:2/flagdb", "up", "--path", "testdata"})
+
+	// Assert
+	suite.NoError(err)
+	suite.migratorMock.AssertExpectations(suite.T())
+}
+
+func (suite *CommanderTestSuite) Test_Up_UsesWholeURL_InCaseOfURLEnvironmentVariableWithComma() {
+	// Arrange
+	suite.T().Setenv(migrateflag.URLEnvVar, "host=localhost dbname=db options='-c search_path=a,b'")
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "host=localhost dbname=db options='-c search_path=a,b'",
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.newApp().Run([]string{"migrate", "up", "--path", "testdata"})
+
+	// Assert
+	suite.NoError(err)
+	suite.migratorMock.AssertExpectations(suite.T())
+}
+
+func (suite *CommanderTestSuite) Test_Up_UsesCommandURLs_InCaseOfRepeatedURL() {
+	// Arrange
+	suite.T().Setenv(migrateflag.URLEnvVar, "postgres://localhost:1/envdb")
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "postgres://localhost:2/db1",
+		URLs:                        []string{"postgres://localhost:2/db1", "postgres://localhost:2/db2"},
+		Concurrency:                 4,
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.newApp().Run([]string{"migrate", "--url", "postgres://localhost:3/globaldb", "up", "--path", "testdata",
+		"--url", "postgres://localhost:2/db1", "--url", "postgres://localhost:2/db2"})
+
+	// Assert
+	suite.NoError(err)
+	suite.migratorMock.AssertExpectations(suite.T())
+}
+
+func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfSuccessAndRunTimeoutDuration() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("run-timeout-duration", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "connectionurl",
+			"--run-timeout-duration", "30m",
+		}),
+	)
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		RunTimeoutDuration:          30 * time.Minute,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.commander.Up(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfSuccessAndRetryFlags() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("max-attempts", "", "")
+	suite.flagSet.String("retry-delay-duration", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "connectionurl",
+			"--max-attempts", "5",
+			"--retry-delay-duration", "200ms",
+		}),
+	)
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		MaxAttempts:                 5,
+		RetryDelayDuration:          200 * time.Millisecond,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
 	}
 
-	suite.migratorMock.On("Migrate", args).Return(nil).Once()
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
 
 	// Act
 	err := suite.commander.Up(suite.ctx)
@@ -192,6 +355,299 @@ func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfSuccessAndTimeoutDur
 	suite.NoError(err)
 }
 
+func (suite *CommanderTestSuite) Test_Up_ReturnsError_InCaseOfInvalidMaxAttempts() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("max-attempts", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "connectionurl",
+			"--max-attempts", "0",
+		}),
+	)
+
+	// Act
+	err := suite.commander.Up(suite.ctx)
+
+	// Assert
+	suite.Error(err)
+	suite.Contains(err.Error(), "max-attempts")
+}
+
+func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfSuccessAndTable() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("table", "", "")
+	suite.flagSet.String("schema", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "connectionurl",
+			"--table", "billing_migrations",
+			"--schema", "ops",
+		}),
+	)
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		Table:                       "billing_migrations",
+		Schema:                      "ops",
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.commander.Up(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfSuccessAndSchemas() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("schemas", "", "")
+	suite.flagSet.Bool("continue-on-error", false, "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "connectionurl",
+			"--schemas", "tenant_1, tenant_2,",
+			"--continue-on-error",
+		}),
+	)
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		Schemas:                     []string{"tenant_1", "tenant_2"},
+		ContinueOnError:             true,
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.commander.Up(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Up_ReturnsError_InCaseOfSchemasAndSchemaPattern() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("schemas", "", "")
+	suite.flagSet.String("schema-pattern", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "connectionurl",
+			"--schemas", "tenant_1",
+			"--schema-pattern", "tenant_%",
+		}),
+	)
+
+	// Act
+	err := suite.commander.Up(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "parsing parameters failed: please specify either schemas or schema-pattern")
+}
+
+func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfSuccessAndMultipleURLs() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.Var(&cli.StringSlice{}, "url", "")
+	suite.flagSet.String("urls-file", "", "")
+	suite.flagSet.String("concurrency", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "postgres://host1/db1",
+			"--url", "postgres://host2/db2",
+			"--urls-file", filepath.Join("testdata", "urls.txt"),
+			"--concurrency", "2",
+		}),
+	)
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "postgres://host1/db1",
+		URLs:                        []string{"postgres://host1/db1", "postgres://host2/db2", "postgres://host3/db3", "postgres://host4/db4"},
+		Concurrency:                 2,
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.commander.Up(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Lint_ReturnsError_InCaseOfFindings() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("lint-disable", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--lint-disable", "drop-table, drop-column",
+		}),
+	)
+
+	args := migrator.Args{
+		Path:        "testdata",
+		LintDisable: []string{"drop-table", "drop-column"},
+	}
+
+	suite.migratorMock.On("Lint", args).Return([]lint.Finding{{File: "1_a.up.sql", Rule: lint.MissingDown}}, nil).Once()
+
+	// Act
+	err := suite.commander.Lint(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "found 1 lint warning(s)")
+}
+
+func (suite *CommanderTestSuite) Test_Lint_ReturnsNil_InCaseOfNoFindings() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata"}))
+
+	suite.migratorMock.On("Lint", migrator.Args{Path: "testdata"}).Return(nil, nil).Once()
+
+	// Act
+	err := suite.commander.Lint(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Validate_ReturnsError_InCaseOfProblems() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata"}))
+
+	suite.migratorMock.On("Validate", migrator.Args{Path: "testdata"}).Return([]file.Problem{
+		{File: "1_a.up.sql", Kind: file.UnpairedProblem},
+		{File: "2_b.UP.sql", Kind: file.UnrecognisedProblem},
+	}, nil).Once()
+
+	// Act
+	err := suite.commander.Validate(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "found 2 problem(s) in migration files")
+}
+
+func (suite *CommanderTestSuite) Test_Validate_ReturnsNil_InCaseOfURL() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "connectionurl",
+		}),
+	)
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("Validate", args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.commander.Validate(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Status_ReturnsError_InCaseOfMultipleURLs() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.Var(&cli.StringSlice{}, "url", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "postgres://host1/db1",
+			"--url", "postgres://host2/db2",
+		}),
+	)
+
+	// Act
+	err := suite.commander.Status(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "parsing parameters failed: please specify a single url")
+}
+
+func (suite *CommanderTestSuite) Test_Redo_ReturnsError_InCaseOfSchemas() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("schemas", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "connectionurl",
+			"--schemas", "tenant_1,tenant_2",
+			"1",
+		}),
+	)
+
+	// Act
+	err := suite.commander.Redo(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "parsing parameters failed: please specify schemas or schema-pattern only with up or down")
+}
+
+func (suite *CommanderTestSuite) Test_Status_ReturnsError_InCaseOfSchemaPattern() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("schema-pattern", "", "")
+	suite.Require().NoError(
+		suite.flagSet.Parse([]string{
+			"--path", "testdata",
+			"--url", "connectionurl",
+			"--schema-pattern", "tenant_%",
+		}),
+	)
+
+	// Act
+	err := suite.commander.Status(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "parsing parameters failed: please specify schemas or schema-pattern only with up or down")
+}
+
 func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfSuccessAndTimeout() {
 	// Arrange
 	suite.flagSet.String("path", "", "")
@@ -214,9 +670,10 @@ func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfSuccessAndTimeout()
 		Steps:                       0,
 		TimeoutDuration:             10 * time.Second,
 		DBConnectionTimeoutDuration: 10 * time.Second,
+		LockTimeoutDuration:         time.Minute,
 	}
 
-	suite.migratorMock.On("Migrate", args).Return(nil).Once()
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
 
 	// Act
 	err := suite.commander.Up(suite.ctx)
@@ -248,9 +705,10 @@ func (suite *CommanderTestSuite) Test_Up_ReturnsNil_InCaseOfArgumentN() {
 		Steps:                       10,
 		TimeoutDuration:             10 * time.Second,
 		DBConnectionTimeoutDuration: 10 * time.Second,
+		LockTimeoutDuration:         time.Minute,
 	}
 
-	suite.migratorMock.On("Migrate", args).Return(nil).Once()
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
 
 	// Act
 	err := suite.commander.Up(suite.ctx)
@@ -302,9 +760,10 @@ func (suite *CommanderTestSuite) Test_Down_ReturnsError_InCaseOfMigratorError()
 		Steps:                       123,
 		TimeoutDuration:             10 * time.Second,
 		DBConnectionTimeoutDuration: 10 * time.Second,
+		LockTimeoutDuration:         time.Minute,
 	}
 
-	suite.migratorMock.On("Migrate", args).Return(suite.expectedErr).Once()
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, suite.expectedErr).Once()
 
 	// Act
 	err := suite.commander.Down(suite.ctx)
@@ -349,9 +808,10 @@ func (suite *CommanderTestSuite) Test_Down_ReturnsNil_InCaseOfSuccessAndTimeoutD
 		Steps:                       123,
 		TimeoutDuration:             10 * time.Second,
 		DBConnectionTimeoutDuration: 10 * time.Second,
+		LockTimeoutDuration:         time.Minute,
 	}
 
-	suite.migratorMock.On("Migrate", args).Return(nil).Once()
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
 
 	// Act
 	err := suite.commander.Down(suite.ctx)
@@ -383,9 +843,10 @@ func (suite *CommanderTestSuite) Test_Down_ReturnsNil_InCaseOfSuccessAndTimeout(
 		Steps:                       123,
 		TimeoutDuration:             10 * time.Second,
 		DBConnectionTimeoutDuration: 10 * time.Second,
+		LockTimeoutDuration:         time.Minute,
 	}
 
-	suite.migratorMock.On("Migrate", args).Return(nil).Once()
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, nil).Once()
 
 	// Act
 	err := suite.commander.Down(suite.ctx)
@@ -393,3 +854,271 @@ func (suite *CommanderTestSuite) Test_Down_ReturnsNil_InCaseOfSuccessAndTimeout(
 	// Assert
 	suite.NoError(err)
 }
+
+func (suite *CommanderTestSuite) Test_Status_ReturnsError_InCaseOfMissingURL() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata"}))
+
+	// Act
+	err := suite.commander.Status(suite.ctx)
+
+	// Assert
+	suite.EqualError(errors.Cause(err), "please specify url")
+}
+
+func (suite *CommanderTestSuite) Test_Status_ReturnsError_InCaseOfPendingMigrations() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl"}))
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	statuses := migrator.Statuses{
+		{Version: 1, State: migrator.Applied},
+		{Version: 2, State: migrator.Pending},
+		{Version: 3, State: migrator.Orphaned},
+	}
+
+	suite.migratorMock.On("Status", args).Return(statuses, nil).Once()
+
+	// Act
+	err := suite.commander.Status(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "found 1 pending, 1 orphaned and 0 dirty migration(s)")
+}
+
+func (suite *CommanderTestSuite) Test_Status_ReturnsNil_InCaseOfAllMigrationsApplied() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl"}))
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	statuses := migrator.Statuses{
+		{Version: 1, State: migrator.Applied},
+	}
+
+	suite.migratorMock.On("Status", args).Return(statuses, nil).Once()
+
+	// Act
+	err := suite.commander.Status(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Force_ReturnsError_InCaseOfMissingVersion() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl"}))
+
+	// Act
+	err := suite.commander.Force(suite.ctx)
+
+	// Assert
+	suite.EqualError(errors.Cause(err), "please specify <version>")
+}
+
+func (suite *CommanderTestSuite) Test_Force_ReturnsError_InCaseOfInvalidDirection() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl", "1494538317", "sideways"}))
+
+	// Act
+	err := suite.commander.Force(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "parsing <direction> failed")
+}
+
+func (suite *CommanderTestSuite) Test_Force_ReturnsNil_InCaseOfSuccess() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl", "1494538317", "down"}))
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		Direction:                   direction.Down,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+		Version:                     1494538317,
+	}
+
+	suite.migratorMock.On("Force", args).Return(nil).Once()
+
+	// Act
+	err := suite.commander.Force(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_History_ReturnsError_InCaseOfInvalidSince() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("since", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl", "--since", "yesterday"}))
+
+	// Act
+	err := suite.commander.History(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "parsing since failed")
+}
+
+func (suite *CommanderTestSuite) Test_History_ReturnsNil_InCaseOfVersionAndDates() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.String("since", "", "")
+	suite.flagSet.String("until", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl",
+		"--since", "2026-10-01T12:00:00+02:00", "--until", "2026-10-16", "1494538317"}))
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+		Version:                     1494538317,
+		Since:                       time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+		Until:                       time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+	}
+
+	suite.migratorMock.On("History", args).Return(nil, nil).Once()
+
+	// Act
+	err := suite.commander.History(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Goto_ReturnsError_InCaseOfMigratorError() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl", "1494538317"}))
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+		Version:                     1494538317,
+	}
+
+	suite.migratorMock.On("GotoContext", context.Background(), args).Return(suite.expectedErr).Once()
+
+	// Act
+	err := suite.commander.Goto(suite.ctx)
+
+	// Assert
+	suite.EqualError(err, "migrating to version 1494538317 failed: failure")
+}
+
+func (suite *CommanderTestSuite) Test_Redo_ReturnsNil_InCaseOfDefaultN() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl"}))
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		Steps:                       1,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("RedoContext", context.Background(), args).Return(nil).Once()
+
+	// Act
+	err := suite.commander.Redo(suite.ctx)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *CommanderTestSuite) Test_Up_ReturnsExitError_InCaseOfPendingMigrationsInDryRun() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.flagSet.Bool("dry-run", false, "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl", "--dry-run"}))
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+		DryRun:                      true,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, errors.Annotate(migrator.ErrPendingMigrations, "migrating failed")).Once()
+
+	// Act
+	err := suite.commander.Up(suite.ctx)
+
+	// Assert
+	if suite.Implements((*cli.ExitCoder)(nil), err) {
+		suite.Equal(ExitCodePendingMigrations, err.(cli.ExitCoder).ExitCode())
+	}
+
+	suite.EqualError(err, "migrating up failed: migrating failed: there are migrations to apply")
+}
+
+func (suite *CommanderTestSuite) Test_Up_ReturnsExitError_InCaseOfInterruptedMigration() {
+	// Arrange
+	suite.flagSet.String("path", "", "")
+	suite.flagSet.String("url", "", "")
+	suite.Require().NoError(suite.flagSet.Parse([]string{"--path", "testdata", "--url", "connectionurl"}))
+
+	args := migrator.Args{
+		Path:                        "testdata",
+		URL:                         "connectionurl",
+		Direction:                   direction.Up,
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+		LockTimeoutDuration:         time.Minute,
+	}
+
+	suite.migratorMock.On("MigrateContext", context.Background(), args).Return(nil, errors.Annotate(migrator.ErrInterrupted, "migrating failed")).Once()
+
+	// Act
+	err := suite.commander.Up(suite.ctx)
+
+	// Assert
+	if suite.Implements((*cli.ExitCoder)(nil), err) {
+		suite.Equal(ExitCodeInterrupted, err.(cli.ExitCoder).ExitCode())
+	}
+
+	suite.EqualError(err, "migrating up failed: migrating failed: migrating was interrupted")
+}
@@ -2,17 +2,52 @@ package driver
 
 import (
 	"context"
+	"time"
 
+	"github.com/juju/errors"
 	"github.com/wallester/migrate/direction"
 	"github.com/wallester/migrate/file"
 	"github.com/wallester/migrate/version"
 )
 
+// DefaultTableName is the name of the migrations table if none is given
+const DefaultTableName = "schema_migrations"
+
+// ErrNoMigrationsTable is returned by SelectMigrations and SelectHistory if the migrations or history table
+// or its schema does not exist
+var ErrNoMigrationsTable = errors.New("migrations table does not exist")
+
+// Table represents the migrations table, an empty schema means the current schema.
+// SearchPath sets search_path of the connection to Schema, so that migrations run in it.
+type Table struct {
+	Schema     string
+	Name       string
+	SearchPath bool
+}
+
+// HistoryFilter restricts the selected migration history, zero values match everything.
+// Since and Until bound the start time of the migrations, Until is exclusive.
+type HistoryFilter struct {
+	Version int64
+	Since   time.Time
+	Until   time.Time
+}
+
 // Driver represents database driver interface.
 type IDriver interface {
-	Open(ctx context.Context, url string) error
+	Open(ctx context.Context, url string, table Table) error
 	CreateMigrationsTable(ctx context.Context) error
-	SelectAllMigrations(ctx context.Context) (version.Versions, error)
+	Lock(ctx context.Context) error
+	Unlock(ctx context.Context) error
+	SelectMigrations(ctx context.Context) (version.Migrations, error)
+	SelectSchemas(ctx context.Context, pattern string) ([]string, error)
+	SelectHistory(ctx context.Context, filter HistoryFilter) ([]version.HistoryEntry, error)
+	SetHooks(hooks file.Hooks)
 	Migrate(ctx context.Context, f file.File, d direction.Direction) error
+	Execute(ctx context.Context, f file.File) error
+	UpdateChecksum(ctx context.Context, f file.File) error
+	Force(ctx context.Context, f file.File, d direction.Direction) error
+	Script(f file.File, d direction.Direction) string
+	MigrationsTableScript() string
 	Close() error
 }
@@ -17,8 +17,8 @@ type Mock struct {
 var _ IDriver = (*Mock)(nil)
 
 // Open is a mock method
-func (m *Mock) Open(ctx context.Context, url string) error {
-	args := m.Called(ctx, url)
+func (m *Mock) Open(ctx context.Context, url string, table Table) error {
+	args := m.Called(ctx, url, table)
 	return args.Error(0)
 }
 
@@ -28,21 +28,88 @@ func (m *Mock) CreateMigrationsTable(ctx context.Context) error {
 	return args.Error(0)
 }
 
-// SelectAllMigrations is a mock method
-func (m *Mock) SelectAllMigrations(ctx context.Context) (version.Versions, error) {
+// Lock is a mock method
+func (m *Mock) Lock(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// Unlock is a mock method
+func (m *Mock) Unlock(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// SelectMigrations is a mock method
+func (m *Mock) SelectMigrations(ctx context.Context) (version.Migrations, error) {
 	args := m.Called(ctx)
 	if args.Get(0) != nil {
-		return args.Get(0).(version.Versions), args.Error(1)
+		return args.Get(0).(version.Migrations), args.Error(1)
 	}
 
 	return nil, args.Error(1)
 }
 
+// SelectSchemas is a mock method
+func (m *Mock) SelectSchemas(ctx context.Context, pattern string) ([]string, error) {
+	args := m.Called(ctx, pattern)
+	if args.Get(0) != nil {
+		return args.Get(0).([]string), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+// SelectHistory is a mock method
+func (m *Mock) SelectHistory(ctx context.Context, filter HistoryFilter) ([]version.HistoryEntry, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) != nil {
+		return args.Get(0).([]version.HistoryEntry), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+// SetHooks is a mock method
+func (m *Mock) SetHooks(hooks file.Hooks) {
+	m.Called(hooks)
+}
+
 func (m *Mock) Migrate(ctx context.Context, f file.File, d direction.Direction) error {
 	args := m.Called(ctx, f, d)
 	return args.Error(0)
 }
 
+// Execute is a mock method
+func (m *Mock) Execute(ctx context.Context, f file.File) error {
+	args := m.Called(ctx, f)
+	return args.Error(0)
+}
+
+// UpdateChecksum is a mock method
+func (m *Mock) UpdateChecksum(ctx context.Context, f file.File) error {
+	args := m.Called(ctx, f)
+	return args.Error(0)
+}
+
+// Force is a mock method
+func (m *Mock) Force(ctx context.Context, f file.File, d direction.Direction) error {
+	args := m.Called(ctx, f, d)
+	return args.Error(0)
+}
+
+// Script is a mock method
+func (m *Mock) Script(f file.File, d direction.Direction) string {
+	args := m.Called(f, d)
+	return args.String(0)
+}
+
+// MigrationsTableScript is a mock method
+func (m *Mock) MigrationsTableScript() string {
+	args := m.Called()
+	return args.String(0)
+}
+
 // Close is a mock method
 func (m *Mock) Close() error {
 	args := m.Called()
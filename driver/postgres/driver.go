@@ -3,9 +3,13 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/juju/errors"
-	_ "github.com/lib/pq" // import driver
+	"github.com/lib/pq"
 	"github.com/wallester/migrate/direction"
 	"github.com/wallester/migrate/driver"
 	"github.com/wallester/migrate/file"
@@ -13,18 +17,64 @@ import (
 )
 
 type Postgres struct {
-	connection *sql.DB
+	connection     *sql.DB
+	lockConnection *sql.Conn
+	lockKey        int64
+	external       bool
+	table          driver.Table
+	hooks          file.Hooks
+	hostname       string
 }
 
 var _ driver.IDriver = (*Postgres)(nil)
 
 // New returns new instance
 func New() *Postgres {
-	return &Postgres{}
+	return &Postgres{
+		table:    driver.Table{Name: driver.DefaultTableName},
+		hostname: hostname(),
+	}
+}
+
+// NewWithDB returns new instance that uses an already open database connection.
+// Open only pings the connection and Close leaves it open.
+func NewWithDB(connection *sql.DB) *Postgres {
+	return &Postgres{
+		connection: connection,
+		external:   true,
+		table:      driver.Table{Name: driver.DefaultTableName},
+		hostname:   hostname(),
+	}
 }
 
-// Open opens database connection
-func (db *Postgres) Open(ctx context.Context, url string) error {
+// Open opens database connection that records migrations in the given table
+func (db *Postgres) Open(ctx context.Context, url string, table driver.Table) error {
+	if table.Name == "" {
+		table.Name = driver.DefaultTableName
+	}
+
+	db.table = table
+	db.hooks = file.Hooks{}
+
+	if table.SearchPath {
+		if db.external {
+			return errors.New("setting search path requires a database URL")
+		}
+
+		var err error
+		if url, err = withSearchPath(url, table.Schema); err != nil {
+			return err
+		}
+	}
+
+	if db.external {
+		if err := db.connection.PingContext(ctx); err != nil {
+			return errors.Annotate(err, "pinging database failed")
+		}
+
+		return nil
+	}
+
 	connection, err := sql.Open("postgres", url)
 	if err != nil {
 		return errors.Annotate(err, "connecting to database failed")
@@ -41,6 +91,10 @@ func (db *Postgres) Open(ctx context.Context, url string) error {
 
 // Close closes database connection
 func (db *Postgres) Close() error {
+	if db.external {
+		return nil
+	}
+
 	if err := db.connection.Close(); err != nil {
 		return errors.Annotate(err, "closing database connection failed")
 	}
@@ -48,28 +102,111 @@ func (db *Postgres) Close() error {
 	return nil
 }
 
-// SelectAllMigrations selects existing migrations
-func (db *Postgres) SelectAllMigrations(ctx context.Context) (version.Versions, error) {
+// Lock acquires an advisory lock keyed on the database, the schema and the migrations table name,
+// waiting until the lock is acquired or the context is done.
+// The current schema is used if no schema is given, so that both name the same table.
+func (db *Postgres) Lock(ctx context.Context) error {
+	connection, err := db.connection.Conn(ctx)
+	if err != nil {
+		return errors.Annotate(err, "getting database connection failed")
+	}
+
+	var key int64
+	if err := connection.QueryRowContext(ctx, `
+		SELECT hashtext(current_database() || '.' || COALESCE(NULLIF($1, ''), current_schema(), '') || '.' || $2)
+	`, db.table.Schema, db.table.Name).Scan(&key); err != nil {
+		return closeConnection(connection, errors.Annotate(err, "computing advisory lock key failed"))
+	}
+
+	for {
+		var locked bool
+		if err := connection.QueryRowContext(ctx, `
+			SELECT pg_try_advisory_lock($1)
+		`, key).Scan(&locked); err != nil {
+			return closeConnection(connection, errors.Annotate(err, "acquiring advisory lock failed"))
+		}
+
+		if locked {
+			db.lockConnection, db.lockKey = connection, key
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return closeConnection(connection, errors.Annotate(ctx.Err(), "waiting for advisory lock failed"))
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// Unlock releases the advisory lock acquired by Lock
+func (db *Postgres) Unlock(ctx context.Context) error {
+	if db.lockConnection == nil {
+		return nil
+	}
+
+	connection := db.lockConnection
+	db.lockConnection = nil
+
+	if _, err := connection.ExecContext(ctx, `
+		SELECT pg_advisory_unlock($1)
+	`, db.lockKey); err != nil {
+		return closeConnection(connection, errors.Annotate(err, "releasing advisory lock failed"))
+	}
+
+	return closeConnection(connection, nil)
+}
+
+// SelectMigrations selects existing migrations with their details.
+// Tables created by older versions, which lack the checksum, dirty and name columns, are read without them.
+func (db *Postgres) SelectMigrations(ctx context.Context) (version.Migrations, error) {
 	rows, err := db.connection.QueryContext(ctx, `
-		SELECT version FROM schema_migrations
+		SELECT version, COALESCE(name, ''), applied_at, checksum, dirty FROM `+db.tableName()+`
 	`)
+	if isUndefinedColumn(err) {
+		rows, err = db.connection.QueryContext(ctx, `
+			SELECT version, '', applied_at, NULL::text, false FROM `+db.tableName()+`
+		`)
+	}
+
 	if err != nil {
-		return nil, errors.Annotate(err, "selecting existing migration versions failed")
+		if isUndefined(err) {
+			return nil, driver.ErrNoMigrationsTable
+		}
+
+		return nil, errors.Annotate(err, "selecting existing migrations failed")
 	}
 
-	var exists struct{}
-	migrated := make(version.Versions)
+	migrations := make(version.Migrations)
 	for rows.Next() {
-		var v int64
-		if err := rows.Scan(&v); err != nil {
+		var (
+			v         int64
+			name      string
+			appliedAt sql.NullTime
+			checksum  sql.NullString
+			dirty     bool
+		)
+
+		if err := rows.Scan(&v, &name, &appliedAt, &checksum, &dirty); err != nil {
 			if err := rows.Close(); err != nil {
 				return nil, errors.Annotate(err, "closing rows failed")
 			}
 
-			return nil, errors.Annotate(err, "scanning version failed")
+			return nil, errors.Annotate(err, "scanning migration failed")
 		}
 
-		migrated[v] = exists
+		m := version.Migration{
+			Version:  v,
+			Name:     name,
+			Checksum: checksum.String,
+			Dirty:    dirty,
+		}
+
+		if appliedAt.Valid {
+			m.AppliedAt = &appliedAt.Time
+		}
+
+		migrations[v] = m
 	}
 
 	if rows.Err() != nil {
@@ -80,69 +217,430 @@ func (db *Postgres) SelectAllMigrations(ctx context.Context) (version.Versions,
 		return nil, errors.Annotate(err, "closing rows failed")
 	}
 
-	return migrated, nil
+	return migrations, nil
 }
 
-// CreateMigrationsTable creates migrations table if it does not exist yet
-func (db *Postgres) CreateMigrationsTable(ctx context.Context) error {
-	if _, err := db.connection.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS schema_migrations(
-			version bigint not null primary key,
-			applied_at timestamp without time zone
-		)
-	`); err != nil {
-		return errors.Annotate(err, "creating schema_migrations table failed")
+// SelectSchemas selects names of schemas matching the LIKE pattern
+func (db *Postgres) SelectSchemas(ctx context.Context, pattern string) ([]string, error) {
+	rows, err := db.connection.QueryContext(ctx, `
+		SELECT nspname FROM pg_namespace WHERE nspname LIKE $1 ORDER BY nspname
+	`, pattern)
+	if err != nil {
+		return nil, errors.Annotate(err, "selecting schemas failed")
 	}
 
-	var appliedAtExists bool
-	if err := db.connection.QueryRowContext(ctx, `
-		SELECT EXISTS (
-			SELECT
-				1
-			FROM
-				information_schema.columns
-			WHERE
-				table_name = 'schema_migrations'
-			AND
-				column_name = 'applied_at'
+	var schemas []string
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			if err := rows.Close(); err != nil {
+				return nil, errors.Annotate(err, "closing rows failed")
+			}
+
+			return nil, errors.Annotate(err, "scanning schema failed")
+		}
+
+		schemas = append(schemas, schema)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, errors.Annotate(err, "closing rows failed")
+	}
+
+	return schemas, nil
+}
+
+// SelectHistory selects the recorded up and down migrations in the order they were executed
+func (db *Postgres) SelectHistory(ctx context.Context, filter driver.HistoryFilter) ([]version.HistoryEntry, error) {
+	rows, err := db.connection.QueryContext(ctx, `
+		SELECT
+			id,
+			version,
+			direction,
+			filename,
+			COALESCE(checksum, ''),
+			started_at,
+			finished_at,
+			EXTRACT(EPOCH FROM duration)::float8,
+			db_user,
+			COALESCE(client_hostname, ''),
+			COALESCE(tool_version, '')
+		FROM
+			`+db.historyTableName()+`
+		WHERE
+			($1::bigint = 0 OR version = $1)
+		AND
+			($2::timestamp IS NULL OR started_at >= $2)
+		AND
+			($3::timestamp IS NULL OR started_at < $3)
+		ORDER BY
+			id
+	`, filter.Version, nullTime(filter.Since), nullTime(filter.Until))
+	if err != nil {
+		if isUndefined(err) {
+			return nil, driver.ErrNoMigrationsTable
+		}
+
+		return nil, errors.Annotate(err, "selecting migration history failed")
+	}
+
+	var history []version.HistoryEntry
+	for rows.Next() {
+		var (
+			e        version.HistoryEntry
+			duration float64
 		)
-	`).Scan(&appliedAtExists); err != nil {
-		return errors.Annotate(err, "checking if applied_at timestamp exists failed")
+
+		if err := rows.Scan(&e.ID, &e.Version, &e.Direction, &e.File, &e.Checksum, &e.StartedAt, &e.FinishedAt,
+			&duration, &e.DBUser, &e.ClientHostname, &e.ToolVersion); err != nil {
+			if err := rows.Close(); err != nil {
+				return nil, errors.Annotate(err, "closing rows failed")
+			}
+
+			return nil, errors.Annotate(err, "scanning migration history failed")
+		}
+
+		e.Duration = time.Duration(duration * float64(time.Second))
+		history = append(history, e)
+	}
+
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, errors.Annotate(err, "closing rows failed")
 	}
 
-	if !appliedAtExists {
+	return history, nil
+}
+
+// CreateMigrationsTable creates migrations and migration history tables if they do not exist yet
+func (db *Postgres) CreateMigrationsTable(ctx context.Context) error {
+	if db.table.Schema != "" {
 		if _, err := db.connection.ExecContext(ctx, `
-			ALTER TABLE schema_migrations ADD COLUMN applied_at timestamp without time zone
+			CREATE SCHEMA IF NOT EXISTS `+pq.QuoteIdentifier(db.table.Schema)+`
 		`); err != nil {
-			return errors.Annotate(err, "adding applied_at timestamp failed")
+			return errors.Annotatef(err, "creating %s schema failed", db.table.Schema)
 		}
 	}
 
+	if _, err := db.connection.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+db.tableName()+`(`+migrationsTableColumns+`)
+	`); err != nil {
+		return errors.Annotatef(err, "creating %s table failed", db.table.Name)
+	}
+
+	if err := db.addColumnIfNotExists(ctx, "applied_at", "timestamp without time zone"); err != nil {
+		return errors.Annotate(err, "adding applied_at timestamp failed")
+	}
+
+	if err := db.addColumnIfNotExists(ctx, "checksum", "text"); err != nil {
+		return errors.Annotate(err, "adding checksum failed")
+	}
+
+	if err := db.addColumnIfNotExists(ctx, "dirty", "boolean not null default false"); err != nil {
+		return errors.Annotate(err, "adding dirty flag failed")
+	}
+
+	if err := db.addColumnIfNotExists(ctx, "name", "text"); err != nil {
+		return errors.Annotate(err, "adding repeatable migration name failed")
+	}
+
+	if _, err := db.connection.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+db.historyTableName()+`(`+historyTableColumns+`)
+	`); err != nil {
+		return errors.Annotatef(err, "creating %s table failed", db.historyName())
+	}
+
 	return nil
 }
 
+// MigrationsTableScript returns SQL that creates the migrations and migration history tables if they do not exist yet
+func (db *Postgres) MigrationsTableScript() string {
+	var b strings.Builder
+	b.WriteString("-- " + db.table.Name + "\n")
+
+	if db.table.Schema != "" {
+		b.WriteString("CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(db.table.Schema) + ";\n")
+	}
+
+	b.WriteString("CREATE TABLE IF NOT EXISTS " + db.tableName() + "(" + migrationsTableColumns + ");\n")
+	b.WriteString("CREATE TABLE IF NOT EXISTS " + db.historyTableName() + "(" + historyTableColumns + ");\n")
+
+	return b.String()
+}
+
+// SetHooks sets the hooks that Migrate and Script execute before and after every migration file
+func (db *Postgres) SetHooks(hooks file.Hooks) {
+	db.hooks = hooks
+}
+
+// Migrate executes the migration file between the before and after each hooks
+// and records it in the migrations and history tables.
+// Go migrations run their function within the transaction in place of SQL.
+// Timeout directives are applied as statement_timeout and lock_timeout of the migration.
+// Files with the no-transaction directive are executed outside of a transaction on a single connection,
+// their version is marked as dirty until they have been executed successfully.
+// Failures of transactions caused by lock timeouts, deadlocks or serialization conflicts are
+// returned as driver.RetryableError.
 func (db *Postgres) Migrate(ctx context.Context, f file.File, d direction.Direction) error {
+	if f.NoTransaction && f.Func == nil {
+		return db.migrateWithoutTransaction(ctx, f, d)
+	}
+
+	return classify(db.migrateInTransaction(ctx, f, d))
+}
+
+// Execute executes the SQL file without recording it on any pooled connection,
+// so session settings of the file do not apply to migrations
+func (db *Postgres) Execute(ctx context.Context, f file.File) error {
+	if _, err := db.connection.ExecContext(ctx, f.SQL); err != nil {
+		return errors.Annotatef(err, "executing %s failed", f.Base)
+	}
+
+	return nil
+}
+
+// UpdateChecksum updates checksum of an already migrated migration
+func (db *Postgres) UpdateChecksum(ctx context.Context, f file.File) error {
+	if _, err := db.connection.ExecContext(ctx, `
+		UPDATE `+db.tableName()+` SET checksum = $2 WHERE version = $1
+	`, f.Version, f.Checksum()); err != nil {
+		return errors.Annotatef(err, "updating checksum of %s failed", f.Base)
+	}
+
+	return nil
+}
+
+// Force marks the migration as applied (up) or not applied (down) without executing it
+func (db *Postgres) Force(ctx context.Context, f file.File, d direction.Direction) error {
+	if _, err := db.connection.ExecContext(ctx, db.applyMigrationSQL(d), applyMigrationArgs(f, d)...); err != nil {
+		return errors.Annotatef(err, "forcing version %d failed", f.Version)
+	}
+
+	return nil
+}
+
+// Script returns SQL that is equivalent to running Migrate with the given file
+func (db *Postgres) Script(f file.File, d direction.Direction) string {
+	var b strings.Builder
+	b.WriteString("-- " + f.Base + "\n")
+
+	if f.NoTransaction {
+		b.WriteString(scriptStatement(db.markDirtySQL(d), applyMigrationArgs(f, d)))
+	} else {
+		b.WriteString("BEGIN;\n")
+	}
+
+	if db.hooks.BeforeEach != nil {
+		b.WriteString(scriptBody(db.hooks.BeforeEach.SQL))
+	}
+
+	timeouts := timeoutsSQL(f, "SET LOCAL")
+	if f.NoTransaction {
+		timeouts = timeoutsSQL(f, "SET")
+	}
+
+	b.WriteString(timeouts)
+	b.WriteString(scriptBody(f.SQL))
+
+	if db.hooks.AfterEach != nil {
+		b.WriteString(scriptBody(db.hooks.AfterEach.SQL))
+	}
+
+	b.WriteString(scriptStatement(db.applyMigrationSQL(d), applyMigrationArgs(f, d)))
+	b.WriteString(scriptStatement(db.historySQL(), db.historyArgs(f, d, scriptStartedAt, scriptFinishedAt)))
+
+	if f.NoTransaction && timeouts != "" {
+		b.WriteString(resetTimeoutsSQL)
+	}
+
+	if !f.NoTransaction {
+		b.WriteString("COMMIT;\n")
+	}
+
+	return b.String()
+}
+
+// private
+
+const lockRetryInterval = 100 * time.Millisecond
+
+const migrationsTableColumns = `
+	version bigint not null primary key,
+	applied_at timestamp without time zone,
+	checksum text,
+	dirty boolean not null default false,
+	name text
+`
+
+const historyTableColumns = `
+	id bigserial not null primary key,
+	version bigint not null,
+	direction text not null,
+	filename text not null,
+	checksum text,
+	started_at timestamp without time zone not null,
+	finished_at timestamp without time zone not null,
+	duration interval not null,
+	db_user text not null default current_user,
+	client_hostname text,
+	tool_version text
+`
+
+// tableName returns the quoted, schema qualified migrations table name
+func (db *Postgres) tableName() string {
+	if db.table.Schema == "" {
+		return pq.QuoteIdentifier(db.table.Name)
+	}
+
+	return pq.QuoteIdentifier(db.table.Schema) + "." + pq.QuoteIdentifier(db.table.Name)
+}
+
+// historyTableName returns the quoted, schema qualified migration history table name
+func (db *Postgres) historyTableName() string {
+	if db.table.Schema == "" {
+		return pq.QuoteIdentifier(db.historyName())
+	}
+
+	return pq.QuoteIdentifier(db.table.Schema) + "." + pq.QuoteIdentifier(db.historyName())
+}
+
+// historyName returns the migration history table name, which is derived from the migrations table name
+func (db *Postgres) historyName() string {
+	return db.table.Name + "_history"
+}
+
+// withSearchPath returns the connection string with search_path set to the schema
+func withSearchPath(url, schema string) (string, error) {
+	dsn := url
+	if strings.HasPrefix(url, "postgres://") || strings.HasPrefix(url, "postgresql://") {
+		var err error
+		if dsn, err = pq.ParseURL(url); err != nil {
+			return "", errors.Annotate(err, "parsing database URL failed")
+		}
+	}
+
+	value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(pq.QuoteIdentifier(schema))
+
+	return dsn + " search_path='" + value + "'", nil
+}
+
+// hostname returns the host name of the client or an empty string if it is unknown
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+
+	return name
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func closeConnection(connection *sql.Conn, reasonErr error) error {
+	if err := connection.Close(); err != nil {
+		return errors.Annotate(err, "closing connection failed")
+	}
+
+	return reasonErr
+}
+
+// resetTimeouts resets the timeouts of the migration file on the connection, the reason error takes precedence
+func resetTimeouts(connection *sql.Conn, f file.File, reasonErr error) error {
+	if _, err := connection.ExecContext(context.Background(), resetTimeoutsSQL); err != nil && reasonErr == nil {
+		return errors.Annotatef(err, "resetting timeouts of %s failed", f.Base)
+	}
+
+	return reasonErr
+}
+
+var applyMigrationSQL = map[direction.Direction]string{
+	direction.Up:   "INSERT INTO %s(version, applied_at, checksum, name) VALUES($1, NOW() at time zone 'utc', $2, $3) ON CONFLICT (version) DO UPDATE SET applied_at = EXCLUDED.applied_at, checksum = EXCLUDED.checksum, dirty = false",
+	direction.Down: "DELETE FROM %s WHERE version = $1",
+}
+
+var markDirtySQL = map[direction.Direction]string{
+	direction.Up:   "INSERT INTO %s(version, applied_at, checksum, dirty, name) VALUES($1, NOW() at time zone 'utc', $2, true, $3) ON CONFLICT (version) DO UPDATE SET dirty = true",
+	direction.Down: "UPDATE %s SET dirty = true WHERE version = $1",
+}
+
+const historySQL = "INSERT INTO %s(version, direction, filename, checksum, started_at, finished_at, duration, client_hostname, tool_version) VALUES($1, $2, $3, $4, $5, $6, $6::timestamp - $5::timestamp, NULLIF($7, ''), $8)"
+
+// expression is a script argument that is written as is instead of as a literal
+type expression string
+
+// The script measures the migration by the start of its transaction and the time the history is recorded
+const (
+	scriptStartedAt  = expression("(NOW() at time zone 'utc')")
+	scriptFinishedAt = expression("(clock_timestamp() at time zone 'utc')")
+)
+
+func (db *Postgres) historySQL() string {
+	return fmt.Sprintf(historySQL, db.historyTableName())
+}
+
+func (db *Postgres) historyArgs(f file.File, d direction.Direction, startedAt, finishedAt interface{}) []interface{} {
+	return []interface{}{f.Version, d.ToString(), f.Base, f.Checksum(), startedAt, finishedAt, db.hostname, version.Tool}
+}
+
+func (db *Postgres) applyMigrationSQL(d direction.Direction) string {
+	return fmt.Sprintf(applyMigrationSQL[d], db.tableName())
+}
+
+func (db *Postgres) markDirtySQL(d direction.Direction) string {
+	return fmt.Sprintf(markDirtySQL[d], db.tableName())
+}
+
+func (db *Postgres) migrateInTransaction(ctx context.Context, f file.File, d direction.Direction) error {
 	tx, err := db.connection.BeginTx(ctx, nil)
 	if err != nil {
 		return errors.Annotate(err, "starting database transaction failed")
 	}
 
 	rollback := func(reasonErr error) error {
-		if err := tx.Rollback(); err != nil {
+		// The transaction is already rolled back when ctx has been canceled
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
 			return errors.Annotate(err, "rolling back transaction failed")
 		}
 
 		return reasonErr
 	}
 
-	if _, err := tx.ExecContext(ctx, f.SQL); err != nil {
+	started := time.Now()
+	if err := executeHook(ctx, tx, db.hooks.BeforeEach); err != nil {
+		return rollback(err)
+	}
+
+	if timeouts := timeoutsSQL(f, "SET LOCAL"); timeouts != "" {
+		if _, err := tx.ExecContext(ctx, timeouts); err != nil {
+			return rollback(errors.Annotatef(err, "setting timeouts of %s failed", f.Base))
+		}
+	}
+
+	if err := execute(ctx, tx, f); err != nil {
 		return rollback(errors.Annotatef(err, "executing %s migration failed", f.Base))
 	}
 
-	if _, err := tx.ExecContext(ctx, applyMigrationSQL[d], f.Version); err != nil {
+	if err := executeHook(ctx, tx, db.hooks.AfterEach); err != nil {
+		return rollback(err)
+	}
+
+	if _, err := tx.ExecContext(ctx, db.applyMigrationSQL(d), applyMigrationArgs(f, d)...); err != nil {
 		return rollback(errors.Annotatef(err, "executing %s migration failed", f.Base))
 	}
 
+	if _, err := tx.ExecContext(ctx, db.historySQL(), db.historyArgs(f, d, started.UTC(), time.Now().UTC())...); err != nil {
+		return rollback(errors.Annotatef(err, "recording %s migration history failed", f.Base))
+	}
+
 	if err := tx.Commit(); err != nil {
 		return rollback(errors.Annotate(err, "committing migrations failed"))
 	}
@@ -150,9 +648,223 @@ func (db *Postgres) Migrate(ctx context.Context, f file.File, d direction.Direct
 	return nil
 }
 
-// private
+// migrateWithoutTransaction executes the statements on a single connection,
+// so that settings of the before each hook and timeout directives apply to the migration file
+func (db *Postgres) migrateWithoutTransaction(ctx context.Context, f file.File, d direction.Direction) error {
+	connection, err := db.connection.Conn(ctx)
+	if err != nil {
+		return errors.Annotate(err, "getting database connection failed")
+	}
 
-var applyMigrationSQL = map[direction.Direction]string{
-	direction.Up:   "INSERT INTO schema_migrations(version, applied_at) VALUES($1, NOW() at time zone 'utc')",
-	direction.Down: "DELETE FROM schema_migrations WHERE version = $1",
+	return closeConnection(connection, db.migrateOnConnection(ctx, connection, f, d))
+}
+
+// migrateOnConnection marks the migration as dirty, executes it with the each hooks and records it on the connection
+func (db *Postgres) migrateOnConnection(ctx context.Context, connection *sql.Conn, f file.File, d direction.Direction) error {
+	if _, err := connection.ExecContext(ctx, db.markDirtySQL(d), applyMigrationArgs(f, d)...); err != nil {
+		return errors.Annotatef(err, "marking %s migration as dirty failed", f.Base)
+	}
+
+	started := time.Now()
+	if err := executeHook(ctx, connection, db.hooks.BeforeEach); err != nil {
+		return err
+	}
+
+	timeouts := timeoutsSQL(f, "SET")
+	if timeouts != "" {
+		if _, err := connection.ExecContext(ctx, timeouts); err != nil {
+			return errors.Annotatef(err, "setting timeouts of %s failed", f.Base)
+		}
+	}
+
+	err := db.executeWithoutTransaction(ctx, connection, f)
+	if timeouts != "" {
+		// The connection returns to the pool, so the settings must not outlive the migration
+		err = resetTimeouts(connection, f, err)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := connection.ExecContext(ctx, db.applyMigrationSQL(d), applyMigrationArgs(f, d)...); err != nil {
+		return errors.Annotatef(err, "recording %s migration failed", f.Base)
+	}
+
+	if _, err := connection.ExecContext(ctx, db.historySQL(), db.historyArgs(f, d, started.UTC(), time.Now().UTC())...); err != nil {
+		return errors.Annotatef(err, "recording %s migration history failed", f.Base)
+	}
+
+	return nil
+}
+
+// executeWithoutTransaction executes the migration SQL and the after each hook on the connection
+func (db *Postgres) executeWithoutTransaction(ctx context.Context, connection *sql.Conn, f file.File) error {
+	if _, err := connection.ExecContext(ctx, f.SQL); err != nil {
+		return errors.Annotatef(err, "executing %s migration failed", f.Base)
+	}
+
+	return executeHook(ctx, connection, db.hooks.AfterEach)
+}
+
+// execute runs the Go migration function or executes the migration SQL within the transaction
+func execute(ctx context.Context, tx *sql.Tx, f file.File) error {
+	if f.Func != nil {
+		return f.Func(ctx, tx)
+	}
+
+	_, err := tx.ExecContext(ctx, f.SQL)
+
+	return err
+}
+
+const resetTimeoutsSQL = "RESET statement_timeout;\nRESET lock_timeout;\n"
+
+// timeoutsSQL returns the statements that apply the timeout directives of the file, set is SET or SET LOCAL
+func timeoutsSQL(f file.File, set string) string {
+	var b strings.Builder
+	if f.Timeout > 0 {
+		b.WriteString(fmt.Sprintf("%s statement_timeout = %d;\n", set, milliseconds(f.Timeout)))
+	}
+
+	if f.LockTimeout > 0 {
+		b.WriteString(fmt.Sprintf("%s lock_timeout = %d;\n", set, milliseconds(f.LockTimeout)))
+	}
+
+	return b.String()
+}
+
+// milliseconds rounds the duration up to whole milliseconds, because zero disables the timeout
+func milliseconds(d time.Duration) int64 {
+	return int64((d + time.Millisecond - 1) / time.Millisecond)
+}
+
+// retryableCodes are the SQLSTATE codes of transient conflicts, after which the transaction may be retried
+var retryableCodes = map[pq.ErrorCode]bool{
+	"55P03": true, // lock_not_available
+	"40P01": true, // deadlock_detected
+	"40001": true, // serialization_failure
+}
+
+// classify marks the error as retryable if the server reported a transient conflict
+func classify(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && retryableCodes[pqErr.Code] {
+		return &driver.RetryableError{Err: err}
+	}
+
+	return err
+}
+
+// undefinedCodes are the SQLSTATE codes of a missing schema or table
+var undefinedCodes = map[pq.ErrorCode]bool{
+	"3F000": true, // invalid_schema_name
+	"42P01": true, // undefined_table
+}
+
+// isUndefined returns true if the server reported a missing schema or table
+func isUndefined(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && undefinedCodes[pqErr.Code]
+}
+
+// isUndefinedColumn returns true if the server reported a missing column
+func isUndefinedColumn(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "42703"
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// executeHook executes the hook file if it exists
+func executeHook(ctx context.Context, e execer, hook *file.File) error {
+	if hook == nil {
+		return nil
+	}
+
+	if _, err := e.ExecContext(ctx, hook.SQL); err != nil {
+		return errors.Annotatef(err, "executing %s hook failed", hook.Base)
+	}
+
+	return nil
+}
+
+// applyMigrationArgs returns the arguments of the migrations table statements, the name is NULL for versioned files
+func applyMigrationArgs(f file.File, d direction.Direction) []interface{} {
+	if d == direction.Up {
+		var name interface{}
+		if f.Repeatable {
+			name = f.Base
+		}
+
+		return []interface{}{f.Version, f.Checksum(), name}
+	}
+
+	return []interface{}{f.Version}
+}
+
+// scriptBody returns the SQL terminated by a semicolon and a new line, ready to be appended to a script
+func scriptBody(sql string) string {
+	body := strings.TrimRight(sql, " \t\r\n")
+	if strings.HasSuffix(body, ";") {
+		return body + "\n"
+	}
+
+	return body + "\n;\n"
+}
+
+// scriptStatement returns the statement with its placeholders replaced by literal arguments
+func scriptStatement(statement string, args []interface{}) string {
+	replacements := make([]string, 0, 2*len(args))
+	for i, arg := range args {
+		var literal string
+		switch v := arg.(type) {
+		case nil:
+			literal = "NULL"
+		case expression:
+			literal = string(v)
+		case string:
+			literal = pq.QuoteLiteral(v)
+		default:
+			literal = fmt.Sprintf("%v", v)
+		}
+
+		replacements = append(replacements, fmt.Sprintf("$%d", i+1), literal)
+	}
+
+	return strings.NewReplacer(replacements...).Replace(statement) + ";\n"
+}
+
+func (db *Postgres) addColumnIfNotExists(ctx context.Context, name, definition string) error {
+	var exists bool
+	if err := db.connection.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT
+				1
+			FROM
+				information_schema.columns
+			WHERE
+				table_schema = COALESCE(NULLIF($1, ''), current_schema())
+			AND
+				table_name = $2
+			AND
+				column_name = $3
+		)
+	`, db.table.Schema, db.table.Name, name).Scan(&exists); err != nil {
+		return errors.Annotatef(err, "checking if %s exists failed", name)
+	}
+
+	if exists {
+		return nil
+	}
+
+	if _, err := db.connection.ExecContext(ctx, `
+		ALTER TABLE `+db.tableName()+` ADD COLUMN `+name+` `+definition+`
+	`); err != nil {
+		return errors.Annotatef(err, "adding %s failed", name)
+	}
+
+	return nil
 }
@@ -0,0 +1,299 @@
+package postgres
+
+import (
+	"context"
+	sqldriver "database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/version"
+)
+
+func Test_Script_ReturnsTransactionalScript_InCaseOfUpMigration(t *testing.T) {
+	// Arrange
+	f := file.File{
+		Base:    "1494538273_create_table_users.up.sql",
+		Version: 1494538273,
+		SQL:     "create table users(id int)\n",
+	}
+	db := &Postgres{table: driver.Table{Name: driver.DefaultTableName}, hostname: "ci"}
+
+	// Act
+	script := db.Script(f, direction.Up)
+
+	// Assert
+	assert.Equal(t, "-- 1494538273_create_table_users.up.sql\n"+
+		"BEGIN;\n"+
+		"create table users(id int)\n"+
+		";\n"+
+		"INSERT INTO \"schema_migrations\"(version, applied_at, checksum, name) VALUES(1494538273, NOW() at time zone 'utc', '"+f.Checksum()+"', NULL) ON CONFLICT (version) DO UPDATE SET applied_at = EXCLUDED.applied_at, checksum = EXCLUDED.checksum, dirty = false;\n"+
+		"INSERT INTO \"schema_migrations_history\"(version, direction, filename, checksum, started_at, finished_at, duration, client_hostname, tool_version) VALUES(1494538273, 'up', '1494538273_create_table_users.up.sql', '"+f.Checksum()+"', (NOW() at time zone 'utc'), (clock_timestamp() at time zone 'utc'), (clock_timestamp() at time zone 'utc')::timestamp - (NOW() at time zone 'utc')::timestamp, NULLIF('ci', ''), '"+version.Tool+"');\n"+
+		"COMMIT;\n", script)
+}
+
+func Test_Script_ReturnsScriptWithoutTransaction_InCaseOfNoTransactionDirective(t *testing.T) {
+	// Arrange
+	f := file.File{
+		Base:          "1494538273_create_index.down.sql",
+		Version:       1494538273,
+		SQL:           "-- migrate:no-transaction\ndrop index concurrently users_name_idx;\n",
+		NoTransaction: true,
+	}
+
+	// Act
+	script := (&Postgres{table: driver.Table{Name: driver.DefaultTableName}}).Script(f, direction.Down)
+
+	// Assert
+	assert.Equal(t, "-- 1494538273_create_index.down.sql\n"+
+		"UPDATE \"schema_migrations\" SET dirty = true WHERE version = 1494538273;\n"+
+		"-- migrate:no-transaction\ndrop index concurrently users_name_idx;\n"+
+		"DELETE FROM \"schema_migrations\" WHERE version = 1494538273;\n"+
+		"INSERT INTO \"schema_migrations_history\"(version, direction, filename, checksum, started_at, finished_at, duration, client_hostname, tool_version) VALUES(1494538273, 'down', '1494538273_create_index.down.sql', '"+f.Checksum()+"', (NOW() at time zone 'utc'), (clock_timestamp() at time zone 'utc'), (clock_timestamp() at time zone 'utc')::timestamp - (NOW() at time zone 'utc')::timestamp, NULLIF('', ''), '"+version.Tool+"');\n", script)
+}
+
+func Test_Script_ReturnsQuotedTableName_InCaseOfSchema(t *testing.T) {
+	// Arrange
+	db := &Postgres{table: driver.Table{Schema: "ops", Name: "billing migrations"}}
+	f := file.File{
+		Base:    "1494538273_create_table_users.down.sql",
+		Version: 1494538273,
+		SQL:     "drop table users;",
+	}
+
+	// Act
+	script := db.Script(f, direction.Down)
+
+	// Assert
+	assert.Equal(t, "-- 1494538273_create_table_users.down.sql\n"+
+		"BEGIN;\n"+
+		"drop table users;\n"+
+		"DELETE FROM \"ops\".\"billing migrations\" WHERE version = 1494538273;\n"+
+		"INSERT INTO \"ops\".\"billing migrations_history\"(version, direction, filename, checksum, started_at, finished_at, duration, client_hostname, tool_version) VALUES(1494538273, 'down', '1494538273_create_table_users.down.sql', '"+f.Checksum()+"', (NOW() at time zone 'utc'), (clock_timestamp() at time zone 'utc'), (clock_timestamp() at time zone 'utc')::timestamp - (NOW() at time zone 'utc')::timestamp, NULLIF('', ''), '"+version.Tool+"');\n"+
+		"COMMIT;\n", script)
+}
+
+func Test_Script_RecordsName_InCaseOfRepeatableMigration(t *testing.T) {
+	// Arrange
+	db := &Postgres{table: driver.Table{Name: driver.DefaultTableName}}
+	f := file.File{
+		Base:       "R_views.sql",
+		Version:    file.RepeatableVersion("R_views.sql"),
+		SQL:        "create or replace view v as select 1;",
+		Repeatable: true,
+	}
+
+	// Act
+	script := db.Script(f, direction.Up)
+
+	// Assert
+	assert.Contains(t, script, "INSERT INTO \"schema_migrations\"(version, applied_at, checksum, name) VALUES("+
+		fmt.Sprint(f.Version)+", NOW() at time zone 'utc', '"+f.Checksum()+"', 'R_views.sql') ON CONFLICT")
+}
+
+func Test_Script_ReturnsEachHooksInTransaction_InCaseOfHooks(t *testing.T) {
+	// Arrange
+	db := &Postgres{table: driver.Table{Name: driver.DefaultTableName}}
+	db.SetHooks(file.Hooks{
+		BeforeEach: &file.File{Base: "_before_each.sql", SQL: "set local lock_timeout = '5s'"},
+		AfterEach:  &file.File{Base: "_after_each.sql", SQL: "reset role;\n"},
+	})
+	f := file.File{
+		Base:    "1494538273_create_table_users.down.sql",
+		Version: 1494538273,
+		SQL:     "drop table users;",
+	}
+
+	// Act
+	script := db.Script(f, direction.Down)
+
+	// Assert
+	assert.True(t, strings.HasPrefix(script, "-- 1494538273_create_table_users.down.sql\n"+
+		"BEGIN;\n"+
+		"set local lock_timeout = '5s'\n;\n"+
+		"drop table users;\n"+
+		"reset role;\n"+
+		"DELETE FROM \"schema_migrations\" WHERE version = 1494538273;\n"), script)
+	assert.True(t, strings.HasSuffix(script, "COMMIT;\n"), script)
+}
+
+func Test_Script_ReturnsLocalTimeouts_InCaseOfTimeoutDirectives(t *testing.T) {
+	// Arrange
+	db := &Postgres{table: driver.Table{Name: driver.DefaultTableName}}
+	f := file.File{
+		Base:        "1494538273_backfill.down.sql",
+		Version:     1494538273,
+		SQL:         "update users set name = '';",
+		Timeout:     10 * time.Minute,
+		LockTimeout: 1500 * time.Microsecond,
+	}
+
+	// Act
+	script := db.Script(f, direction.Down)
+
+	// Assert
+	assert.True(t, strings.HasPrefix(script, "-- 1494538273_backfill.down.sql\n"+
+		"BEGIN;\n"+
+		"SET LOCAL statement_timeout = 600000;\n"+
+		"SET LOCAL lock_timeout = 2;\n"+
+		"update users set name = '';\n"), script)
+}
+
+func Test_Script_ResetsTimeouts_InCaseOfNoTransactionDirective(t *testing.T) {
+	// Arrange
+	db := &Postgres{table: driver.Table{Name: driver.DefaultTableName}}
+	f := file.File{
+		Base:          "1494538273_create_index.down.sql",
+		Version:       1494538273,
+		SQL:           "drop index concurrently users_name_idx;",
+		NoTransaction: true,
+		LockTimeout:   3 * time.Second,
+	}
+
+	// Act
+	script := db.Script(f, direction.Down)
+
+	// Assert
+	assert.Contains(t, script, "SET lock_timeout = 3000;\ndrop index concurrently users_name_idx;\n")
+	assert.True(t, strings.HasSuffix(script, "RESET statement_timeout;\nRESET lock_timeout;\n"), script)
+}
+
+func Test_withSearchPath_ReturnsConnectionString_InCaseOfURL(t *testing.T) {
+	// Act
+	dsn, err := withSearchPath("postgres://user@localhost:5432/database?sslmode=disable", "tenant_1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, `dbname='database' host='localhost' port='5432' sslmode='disable' user='user' search_path='"tenant_1"'`, dsn)
+}
+
+func Test_withSearchPath_ReturnsConnectionString_InCaseOfKeyValueConnectionString(t *testing.T) {
+	// Act
+	dsn, err := withSearchPath("host=localhost dbname=database", "it's")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, `host=localhost dbname=database search_path='"it\'s"'`, dsn)
+}
+
+func Test_classify_ReturnsRetryableError_InCaseOfDeadlock(t *testing.T) {
+	// Arrange
+	err := errors.Annotate(&pq.Error{Code: "40P01", Message: "deadlock detected"}, "executing migration failed")
+
+	// Act
+	classified := classify(err)
+
+	// Assert
+	assert.True(t, driver.IsRetryable(classified))
+	assert.Equal(t, err.Error(), classified.Error())
+}
+
+func Test_classify_ReturnsError_InCaseOfOtherErrorCode(t *testing.T) {
+	// Arrange
+	err := errors.Annotate(&pq.Error{Code: "42P01", Message: "relation does not exist"}, "executing migration failed")
+
+	// Act
+	classified := classify(err)
+
+	// Assert
+	assert.False(t, driver.IsRetryable(classified))
+	assert.Equal(t, err, classified)
+}
+
+func Test_isUndefined_ReturnsTrue_InCaseOfMissingTable(t *testing.T) {
+	// Arrange
+	err := &pq.Error{Code: "42P01", Message: `relation "schema_migrations" does not exist`}
+
+	// Act
+	undefined := isUndefined(err)
+
+	// Assert
+	assert.True(t, undefined)
+}
+
+func Test_isUndefined_ReturnsFalse_InCaseOfMissingColumn(t *testing.T) {
+	// Arrange
+	err := &pq.Error{Code: "42703", Message: `column "checksum" does not exist`}
+
+	// Act
+	undefined := isUndefined(err)
+
+	// Assert
+	assert.False(t, undefined)
+}
+
+func Test_SelectMigrations_ReturnsMigrations_InCaseOfLegacyTable(t *testing.T) {
+	// Arrange
+	appliedAt := time.Date(2017, 5, 11, 21, 31, 13, 0, time.UTC)
+	fake := &fakeDB{queries: []fakeQuery{
+		{text: "dirty FROM", err: &pq.Error{Code: "42703", Message: `column "checksum" does not exist`}},
+		{
+			text:    "false FROM",
+			columns: []string{"version", "name", "applied_at", "checksum", "dirty"},
+			rows:    [][]sqldriver.Value{{int64(1494538273), "", appliedAt, nil, false}},
+		},
+	}}
+	db := NewWithDB(fake.open())
+
+	// Act
+	migrations, err := db.SelectMigrations(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, version.Migrations{1494538273: {Version: 1494538273, AppliedAt: &appliedAt}}, migrations)
+}
+
+func Test_SelectMigrations_ReturnsErrNoMigrationsTable_InCaseOfMissingTable(t *testing.T) {
+	// Arrange
+	fake := &fakeDB{queries: []fakeQuery{
+		{text: "FROM", err: &pq.Error{Code: "42P01", Message: `relation "schema_migrations" does not exist`}},
+	}}
+	db := NewWithDB(fake.open())
+
+	// Act
+	migrations, err := db.SelectMigrations(context.Background())
+
+	// Assert
+	assert.Equal(t, driver.ErrNoMigrationsTable, err)
+	assert.Nil(t, migrations)
+}
+
+func Test_MigrationsTableScript_ReturnsCreateStatements_InCaseOfSchema(t *testing.T) {
+	// Arrange
+	db := &Postgres{table: driver.Table{Schema: "tenant_1", Name: driver.DefaultTableName}}
+
+	// Act
+	script := db.MigrationsTableScript()
+
+	// Assert
+	assert.Equal(t, "-- schema_migrations\n"+
+		"CREATE SCHEMA IF NOT EXISTS \"tenant_1\";\n"+
+		"CREATE TABLE IF NOT EXISTS \"tenant_1\".\"schema_migrations\"("+migrationsTableColumns+");\n"+
+		"CREATE TABLE IF NOT EXISTS \"tenant_1\".\"schema_migrations_history\"("+historyTableColumns+");\n", script)
+}
+
+func Test_Lock_UsesCurrentSchemaInLockKey_InCaseOfNoSchema(t *testing.T) {
+	// Arrange
+	fake := &fakeDB{queries: []fakeQuery{
+		{text: "current_schema()", columns: []string{"hashtext"}, rows: [][]sqldriver.Value{{int64(42)}}},
+		{text: "pg_try_advisory_lock($1)", columns: []string{"pg_try_advisory_lock"}, rows: [][]sqldriver.Value{{true}}},
+	}}
+	db := NewWithDB(fake.open())
+
+	// Act
+	lockErr := db.Lock(context.Background())
+	unlockErr := db.Unlock(context.Background())
+
+	// Assert
+	assert.NoError(t, lockErr)
+	assert.NoError(t, unlockErr)
+	assert.Equal(t, int64(42), db.lockKey)
+	assert.Equal(t, []string{"SELECT pg_advisory_unlock($1)"}, fake.executed)
+}
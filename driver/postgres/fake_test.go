@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// fakeQuery answers the queries containing the text with the rows or the error
+type fakeQuery struct {
+	text    string
+	columns []string
+	rows    [][]sqldriver.Value
+	err     error
+}
+
+// fakeDB is a database/sql connector whose connections answer queries with the first matching fake query
+// and record the executed statements
+type fakeDB struct {
+	queries  []fakeQuery
+	executed []string
+}
+
+// open returns a database that answers queries from the fake
+func (f *fakeDB) open() *sql.DB {
+	return sql.OpenDB(f)
+}
+
+// Connect returns a new connection
+func (f *fakeDB) Connect(context.Context) (sqldriver.Conn, error) {
+	return &fakeConn{db: f}, nil
+}
+
+// Driver returns the driver of the connector
+func (f *fakeDB) Driver() sqldriver.Driver {
+	return fakeDriver{db: f}
+}
+
+type fakeDriver struct {
+	db *fakeDB
+}
+
+func (d fakeDriver) Open(string) (sqldriver.Conn, error) {
+	return &fakeConn{db: d.db}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (sqldriver.Stmt, error) {
+	return nil, fmt.Errorf("preparing %q is not supported", query)
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (sqldriver.Tx, error) {
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []sqldriver.NamedValue) (sqldriver.Result, error) {
+	c.db.executed = append(c.db.executed, strings.TrimSpace(query))
+	return sqldriver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	for _, q := range c.db.queries {
+		if !strings.Contains(query, q.text) {
+			continue
+		}
+
+		if q.err != nil {
+			return nil, q.err
+		}
+
+		return &fakeRows{columns: q.columns, rows: q.rows}, nil
+	}
+
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]sqldriver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []sqldriver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+
+	return nil
+}
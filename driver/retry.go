@@ -0,0 +1,27 @@
+package driver
+
+import (
+	"github.com/juju/errors"
+)
+
+// RetryableError represents a failed migration whose transaction may succeed when it is retried,
+// for example after a lock timeout or a deadlock
+type RetryableError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable returns true if the migration failed with a retryable error
+func IsRetryable(err error) bool {
+	var retryable *RetryableError
+	return errors.As(err, &retryable)
+}
@@ -0,0 +1,63 @@
+package file
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// NoTransactionDirective makes the migration run outside of a transaction.
+	NoTransactionDirective = "no-transaction"
+	// TimeoutDirective sets the statement timeout and deadline of the migration, for example -- migrate:timeout 10m.
+	TimeoutDirective = "timeout"
+	// LockTimeoutDirective sets the lock timeout of the migration, for example -- migrate:lock-timeout 3s.
+	LockTimeoutDirective = "lock-timeout"
+)
+
+// private
+
+const directivePrefix = "-- migrate:"
+
+// parseDirectives returns directives from the header comment of a migration file.
+// The header consists of the leading comment and blank lines.
+func parseDirectives(sql string) map[string]string {
+	directives := make(map[string]string)
+
+	scanner := bufio.NewScanner(strings.NewReader(sql))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "--") {
+			break
+		}
+
+		if !strings.HasPrefix(line, directivePrefix) {
+			continue
+		}
+
+		name, value, _ := strings.Cut(strings.TrimPrefix(line, directivePrefix), " ")
+		directives[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return directives
+}
+
+// parseDuration returns the positive duration of the directive or zero if the directive is missing
+func parseDuration(directives map[string]string, name string) (time.Duration, error) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s directive must be a positive duration, for example 10m: %q", name, value)
+	}
+
+	return d, nil
+}
@@ -1,21 +1,42 @@
 package file
 
 import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"hash/fnv"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/wallester/migrate/direction"
 )
 
-// File represents a migration file
+// RepeatablePrefix starts the names of repeatable migration files, for example R_views.sql
+const RepeatablePrefix = "R_"
+
+// Func is a Go migration function that runs within the transaction of the migration
+type Func func(ctx context.Context, tx *sql.Tx) error
+
+// File represents a migration file.
+// Repeatable files have no direction and are keyed by a negative version derived from their name.
+// Go migrations have no SQL, Func is run instead. Zero timeouts mean the defaults of the run.
 type File struct {
-	Base    string
-	Version int64
-	SQL     string
+	Base          string
+	Version       int64
+	SQL           string
+	NoTransaction bool
+	Timeout       time.Duration
+	LockTimeout   time.Duration
+	Repeatable    bool
+	Func          Func
 }
 
 // Create creates a new file in the given path
@@ -27,6 +48,12 @@ func (f File) Create(path string) error {
 	return nil
 }
 
+// Checksum returns SHA-256 checksum of the migration SQL
+func (f File) Checksum() string {
+	sum := sha256.Sum256([]byte(f.SQL))
+	return hex.EncodeToString(sum[:])
+}
+
 // Pair is a pair of migration files; up and down
 type Pair struct {
 	Up   File
@@ -62,30 +89,36 @@ func FindByVersion(version int64, files []File) *File {
 
 // ListFiles lists migration files on a given path
 func ListFiles(path string, d direction.Direction) ([]File, error) {
-	files, err := filepath.Glob(filepath.Join(path, "*_*."+d.ToString()+".sql"))
+	if path == "" {
+		path = "."
+	}
+
+	return ListFilesFS(os.DirFS(path), d)
+}
+
+// ListFilesFS lists migration files in the root of a given file system
+func ListFilesFS(fsys fs.FS, d direction.Direction) ([]File, error) {
+	files, err := fs.Glob(fsys, "*_*."+d.ToString()+".sql")
 	if err != nil {
 		return nil, errors.Annotate(err, "getting migration files failed")
 	}
 
 	migrations := make([]File, 0, len(files))
 	for _, file := range files {
-		base := filepath.Base(file)
+		base := path.Base(file)
 
 		version, err := version(base)
 		if err != nil {
 			return nil, errors.Annotatef(err, "getting version of %s migration failed", base)
 		}
 
-		b, err := os.ReadFile(filepath.Clean(file))
+		f, err := readFile(fsys, file)
 		if err != nil {
-			return nil, errors.Annotate(err, "reading migration file failed")
+			return nil, err
 		}
 
-		migrations = append(migrations, File{
-			Base:    base,
-			Version: *version,
-			SQL:     string(b),
-		})
+		f.Version = *version
+		migrations = append(migrations, *f)
 	}
 
 	if d {
@@ -97,8 +130,79 @@ func ListFiles(path string, d direction.Direction) ([]File, error) {
 	return migrations, nil
 }
 
+// ListRepeatableFiles lists repeatable migration files on a given path
+func ListRepeatableFiles(path string) ([]File, error) {
+	if path == "" {
+		path = "."
+	}
+
+	return ListRepeatableFilesFS(os.DirFS(path))
+}
+
+// ListRepeatableFilesFS lists repeatable migration files in the root of a given file system, sorted by name
+func ListRepeatableFilesFS(fsys fs.FS) ([]File, error) {
+	files, err := fs.Glob(fsys, RepeatablePrefix+"*.sql")
+	if err != nil {
+		return nil, errors.Annotate(err, "getting repeatable migration files failed")
+	}
+
+	migrations := make([]File, 0, len(files))
+	for _, file := range files {
+		f, err := readFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		f.Version = RepeatableVersion(f.Base)
+		f.Repeatable = true
+		migrations = append(migrations, *f)
+	}
+
+	sort.Sort(ByBase(migrations))
+
+	return migrations, nil
+}
+
+// RepeatableVersion returns the negative version that keys a repeatable migration in the migrations table
+func RepeatableVersion(base string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(base))
+
+	return -int64(h.Sum64()>>1) - 1
+}
+
 // private
 
+// readFile reads a migration file and its directives
+func readFile(fsys fs.FS, name string) (*File, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, errors.Annotate(err, "reading migration file failed")
+	}
+
+	base := path.Base(name)
+	directives := parseDirectives(string(b))
+	_, noTransaction := directives[NoTransactionDirective]
+
+	timeout, err := parseDuration(directives, TimeoutDirective)
+	if err != nil {
+		return nil, errors.Annotatef(err, "parsing directives of %s failed", base)
+	}
+
+	lockTimeout, err := parseDuration(directives, LockTimeoutDirective)
+	if err != nil {
+		return nil, errors.Annotatef(err, "parsing directives of %s failed", base)
+	}
+
+	return &File{
+		Base:          base,
+		SQL:           string(b),
+		NoTransaction: noTransaction,
+		Timeout:       timeout,
+		LockTimeout:   lockTimeout,
+	}, nil
+}
+
 // version returns version of migration file
 func version(base string) (*int64, error) {
 	version, err := strconv.ParseInt(strings.Split(base, "_")[0], 10, 64)
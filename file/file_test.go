@@ -3,6 +3,8 @@ package file
 import (
 	"path/filepath"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/wallester/migrate/direction"
@@ -49,3 +51,146 @@ func Test_ListFiles_ReturnsDownMigrationFiles_InCaseOfSuccess(t *testing.T) {
 		assert.NotEmpty(t, files[2].SQL)
 	}
 }
+
+func Test_Checksum_ReturnsSHA256OfSQL_InCaseOfSuccess(t *testing.T) {
+	// Arrange
+	f := File{SQL: "select 1;"}
+
+	// Act
+	checksum := f.Checksum()
+
+	// Assert
+	assert.Equal(t, "354b7196c9ba5fb4b21cf615bb6ec4cd5c07503c34229feef033fc081a8c03f4", checksum)
+}
+
+func Test_parseDirectives_ReturnsHeaderDirectives_InCaseOfSuccess(t *testing.T) {
+	// Arrange
+	sql := "-- Adds an index\n\n-- migrate:no-transaction\n--migrate:ignored\nCREATE INDEX CONCURRENTLY users_name_idx ON users(name);\n-- migrate:after-header\n"
+
+	// Act
+	directives := parseDirectives(sql)
+
+	// Assert
+	assert.Equal(t, map[string]string{NoTransactionDirective: ""}, directives)
+}
+
+func Test_parseDirectives_ReturnsEmptyMap_InCaseOfNoHeader(t *testing.T) {
+	// Act
+	directives := parseDirectives("alter table users add column email text;\n-- migrate:no-transaction\n")
+
+	// Assert
+	assert.Empty(t, directives)
+}
+
+func Test_ListFilesFS_ReturnsUpMigrationFiles_InCaseOfSuccess(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"2_add_column.up.sql":     {Data: []byte("-- migrate:no-transaction\nalter table t add column c int;")},
+		"2_add_column.down.sql":   {Data: []byte("alter table t drop column c;")},
+		"1_create_table.up.sql":   {Data: []byte("create table t();")},
+		"README.md":               {Data: []byte("not a migration")},
+		"nested/3_ignored.up.sql": {Data: []byte("select 1;")},
+		"1_create_table.down.sql": {Data: []byte("drop table t;")},
+	}
+
+	// Act
+	files, err := NewFS(fsys).ListFiles(direction.Up)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []File{
+		{Base: "1_create_table.up.sql", Version: 1, SQL: "create table t();"},
+		{Base: "2_add_column.up.sql", Version: 2, SQL: "-- migrate:no-transaction\nalter table t add column c int;", NoTransaction: true},
+	}, files)
+}
+
+func Test_ListRepeatableFilesFS_ReturnsRepeatableFiles_InCaseOfSuccess(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"R_views.sql":           {Data: []byte("create or replace view v as select 1;")},
+		"R_functions.sql":       {Data: []byte("create or replace function f() returns int as 'select 1' language sql;")},
+		"1_create_table.up.sql": {Data: []byte("create table t();")},
+		"R_notes.txt":           {Data: []byte("not a migration")},
+	}
+
+	// Act
+	files, err := NewFS(fsys).ListRepeatableFiles()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []File{
+		{
+			Base:       "R_functions.sql",
+			Version:    RepeatableVersion("R_functions.sql"),
+			SQL:        "create or replace function f() returns int as 'select 1' language sql;",
+			Repeatable: true,
+		},
+		{
+			Base:       "R_views.sql",
+			Version:    RepeatableVersion("R_views.sql"),
+			SQL:        "create or replace view v as select 1;",
+			Repeatable: true,
+		},
+	}, files)
+}
+
+func Test_RepeatableVersion_ReturnsNegativeVersion_InCaseOfSuccess(t *testing.T) {
+	// Act
+	v := RepeatableVersion("R_views.sql")
+
+	// Assert
+	assert.Less(t, v, int64(0))
+	assert.Equal(t, v, RepeatableVersion("R_views.sql"))
+	assert.NotEqual(t, v, RepeatableVersion("R_functions.sql"))
+}
+
+func Test_ListHooksFS_ReturnsHooks_InCaseOfSuccess(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"_before_each.sql":      {Data: []byte("set lock_timeout = '5s';")},
+		"_after_all.sql":        {Data: []byte("analyze;")},
+		"1_create_table.up.sql": {Data: []byte("create table t();")},
+	}
+
+	// Act
+	hooks, err := NewFS(fsys).ListHooks()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, Hooks{
+		BeforeEach: &File{Base: "_before_each.sql", SQL: "set lock_timeout = '5s';"},
+		AfterAll:   &File{Base: "_after_all.sql", SQL: "analyze;"},
+	}, hooks)
+	assert.False(t, hooks.Empty())
+}
+
+func Test_ListFilesFS_ReturnsTimeouts_InCaseOfTimeoutDirectives(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_backfill.up.sql": {Data: []byte("-- migrate:timeout 10m\n-- migrate:lock-timeout 3s\nupdate t set c = 1;")},
+	}
+
+	// Act
+	files, err := NewFS(fsys).ListFiles(direction.Up)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, 10*time.Minute, files[0].Timeout)
+		assert.Equal(t, 3*time.Second, files[0].LockTimeout)
+	}
+}
+
+func Test_ListFilesFS_ReturnsError_InCaseOfInvalidTimeoutDirective(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_backfill.up.sql": {Data: []byte("-- migrate:timeout ten minutes\nupdate t set c = 1;")},
+	}
+
+	// Act
+	files, err := NewFS(fsys).ListFiles(direction.Up)
+
+	// Assert
+	assert.EqualError(t, err, `parsing directives of 1_backfill.up.sql failed: timeout directive must be a positive duration, for example 10m: "ten minutes"`)
+	assert.Nil(t, files)
+}
@@ -0,0 +1,64 @@
+package file
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/juju/errors"
+)
+
+// Hook files are optional SQL files in the migrations directory that are executed around migrations.
+// The each hooks run on the connection of every migration, the all hooks on any pooled connection,
+// so session settings such as SET ROLE belong into the each hooks.
+const (
+	BeforeAllHook  = "_before_all.sql"
+	BeforeEachHook = "_before_each.sql"
+	AfterEachHook  = "_after_each.sql"
+	AfterAllHook   = "_after_all.sql"
+)
+
+// Hooks represents the hook files of a migrations directory, missing hooks are nil
+type Hooks struct {
+	BeforeAll  *File
+	BeforeEach *File
+	AfterEach  *File
+	AfterAll   *File
+}
+
+// Empty returns true if there are no hook files
+func (h Hooks) Empty() bool {
+	return h.BeforeAll == nil && h.BeforeEach == nil && h.AfterEach == nil && h.AfterAll == nil
+}
+
+// ListHooks lists hook files on a given path
+func ListHooks(path string) (Hooks, error) {
+	if path == "" {
+		path = "."
+	}
+
+	return ListHooksFS(os.DirFS(path))
+}
+
+// ListHooksFS lists hook files in the root of a given file system
+func ListHooksFS(fsys fs.FS) (Hooks, error) {
+	var hooks Hooks
+	for name, hook := range map[string]**File{
+		BeforeAllHook:  &hooks.BeforeAll,
+		BeforeEachHook: &hooks.BeforeEach,
+		AfterEachHook:  &hooks.AfterEach,
+		AfterAllHook:   &hooks.AfterAll,
+	} {
+		f, err := readFile(fsys, name)
+		if errors.Is(errors.Cause(err), fs.ErrNotExist) {
+			continue
+		}
+
+		if err != nil {
+			return Hooks{}, errors.Annotatef(err, "reading %s hook failed", name)
+		}
+
+		*hook = f
+	}
+
+	return hooks, nil
+}
@@ -0,0 +1,84 @@
+package file
+
+import (
+	"io/fs"
+
+	"github.com/wallester/migrate/direction"
+)
+
+// ISource represents a source of migration files
+type ISource interface {
+	ListFiles(d direction.Direction) ([]File, error)
+	ListRepeatableFiles() ([]File, error)
+	ListHooks() (Hooks, error)
+	Validate() ([]Problem, error)
+}
+
+// Dir is a source of migration files in a directory
+type Dir struct {
+	path string
+}
+
+var _ ISource = (*Dir)(nil)
+
+// NewDir returns new instance
+func NewDir(path string) *Dir {
+	return &Dir{
+		path: path,
+	}
+}
+
+// ListFiles lists migration files in the directory
+func (s *Dir) ListFiles(d direction.Direction) ([]File, error) {
+	return ListFiles(s.path, d)
+}
+
+// ListRepeatableFiles lists repeatable migration files in the directory
+func (s *Dir) ListRepeatableFiles() ([]File, error) {
+	return ListRepeatableFiles(s.path)
+}
+
+// ListHooks lists hook files in the directory
+func (s *Dir) ListHooks() (Hooks, error) {
+	return ListHooks(s.path)
+}
+
+// Validate returns structural problems of the migration files in the directory
+func (s *Dir) Validate() ([]Problem, error) {
+	return Validate(s.path)
+}
+
+// FS is a source of migration files in the root of a file system, for example embed.FS.
+// Use fs.Sub to point it to a subdirectory.
+type FS struct {
+	fsys fs.FS
+}
+
+var _ ISource = (*FS)(nil)
+
+// NewFS returns new instance
+func NewFS(fsys fs.FS) *FS {
+	return &FS{
+		fsys: fsys,
+	}
+}
+
+// ListFiles lists migration files in the file system
+func (s *FS) ListFiles(d direction.Direction) ([]File, error) {
+	return ListFilesFS(s.fsys, d)
+}
+
+// ListRepeatableFiles lists repeatable migration files in the file system
+func (s *FS) ListRepeatableFiles() ([]File, error) {
+	return ListRepeatableFilesFS(s.fsys)
+}
+
+// ListHooks lists hook files in the file system
+func (s *FS) ListHooks() (Hooks, error) {
+	return ListHooksFS(s.fsys)
+}
+
+// Validate returns structural problems of the migration files in the file system
+func (s *FS) Validate() ([]Problem, error) {
+	return ValidateFS(s.fsys)
+}
@@ -0,0 +1,178 @@
+package file
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+const (
+	// DuplicateProblem is reported for migration files sharing a version and direction.
+	DuplicateProblem = "duplicate"
+	// UnpairedProblem is reported for up migrations without a down migration and vice versa.
+	UnpairedProblem = "unpaired"
+	// UnrecognisedProblem is reported for files with a version prefix that look like mistyped migrations,
+	// for example 1494538273_add_users.UP.sql or 1494538273_add_users.up.sq.
+	UnrecognisedProblem = "unrecognised"
+	// StrayProblem is reported for other SQL files and files with a direction but without a version prefix,
+	// for example structure.sql or add_users.up.sql, which are ignored.
+	StrayProblem = "stray"
+	// EmptyProblem is reported for migration, repeatable and hook files without content.
+	EmptyProblem = "empty"
+	// OutOfOrderProblem is reported for pending migrations older than the latest applied migration.
+	OutOfOrderProblem = "out-of-order"
+)
+
+// Problem represents a structural problem of the migration files
+type Problem struct {
+	File    string
+	Kind    string
+	Message string
+}
+
+// String returns the problem in the file: kind: message format
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.File, p.Kind, p.Message)
+}
+
+// Blocking returns true if the problem makes migrating up or down unsafe
+func (p Problem) Blocking() bool {
+	return p.Kind == DuplicateProblem || p.Kind == UnrecognisedProblem
+}
+
+// Validate returns structural problems of the migration files on a given path
+func Validate(path string) ([]Problem, error) {
+	if path == "" {
+		path = "."
+	}
+
+	return ValidateFS(os.DirFS(path))
+}
+
+// ValidateFS returns structural problems of the migration files in the root of a given file system,
+// sorted by file name
+func ValidateFS(fsys fs.FS) ([]Problem, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.Annotate(err, "reading migration files failed")
+	}
+
+	var problems []Problem
+	versions := map[string]map[int64][]string{"up": {}, "down": {}}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		recognised := isHook(name) || isRepeatable(name)
+		if m := migrationPattern.FindStringSubmatch(name); m != nil {
+			v, err := strconv.ParseInt(m[1], 10, 64)
+			if err != nil {
+				problems = append(problems, Problem{File: name, Kind: UnrecognisedProblem, Message: "version is out of range"})
+				continue
+			}
+
+			versions[m[2]][v] = append(versions[m[2]][v], name)
+			recognised = true
+		}
+
+		if !recognised {
+			if problem, ok := unrecognised(name); ok {
+				problems = append(problems, problem)
+			}
+
+			continue
+		}
+
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, errors.Annotate(err, "reading migration file failed")
+		}
+
+		if strings.TrimSpace(string(b)) == "" {
+			problems = append(problems, Problem{File: name, Kind: EmptyProblem, Message: "file is empty"})
+		}
+	}
+
+	for d, other := range map[string]string{"up": "down", "down": "up"} {
+		for v, names := range versions[d] {
+			for _, name := range names[1:] {
+				problems = append(problems, Problem{
+					File:    name,
+					Kind:    DuplicateProblem,
+					Message: fmt.Sprintf("version %d is also used by %s", v, names[0]),
+				})
+			}
+
+			if _, ok := versions[other][v]; !ok {
+				problems = append(problems, Problem{
+					File:    names[0],
+					Kind:    UnpairedProblem,
+					Message: fmt.Sprintf("%s migration of version %d is missing", other, v),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].File != problems[j].File {
+			return problems[i].File < problems[j].File
+		}
+
+		return problems[i].Kind < problems[j].Kind
+	})
+
+	return problems, nil
+}
+
+// private
+
+var (
+	migrationPattern = regexp.MustCompile(`^([0-9]+)_.+\.(up|down)\.sql$`)
+	directionPattern = regexp.MustCompile(`(?i)\.(up|down)\.`)
+	versionPattern   = regexp.MustCompile(`^[0-9]+[_.]`)
+)
+
+// unrecognised returns the problem of a file that looks like a migration but is not recognised.
+// Files with a version prefix are likely mistyped migrations, other files are stray.
+func unrecognised(name string) (Problem, bool) {
+	hasDirection := directionPattern.MatchString(name)
+	if !strings.Contains(strings.ToLower(name), ".sql") && !hasDirection {
+		return Problem{}, false
+	}
+
+	problem := Problem{File: name, Kind: UnrecognisedProblem}
+	switch {
+	case !versionPattern.MatchString(name) && hasDirection:
+		problem.Kind, problem.Message = StrayProblem, "expected a numeric version prefix, for example 1494538273_create_table_users.up.sql"
+	case !versionPattern.MatchString(name):
+		problem.Kind, problem.Message = StrayProblem, "not a migration, repeatable migration or hook file, it is ignored"
+	case !strings.HasSuffix(name, ".up.sql") && !strings.HasSuffix(name, ".down.sql"):
+		problem.Message = "expected a lower case .up.sql or .down.sql suffix"
+	default:
+		problem.Message = "expected a <version>_<name> file name"
+	}
+
+	return problem, true
+}
+
+func isRepeatable(name string) bool {
+	return strings.HasPrefix(name, RepeatablePrefix) && strings.HasSuffix(name, ".sql") &&
+		!strings.HasSuffix(name, ".up.sql") && !strings.HasSuffix(name, ".down.sql")
+}
+
+func isHook(name string) bool {
+	switch name {
+	case BeforeAllHook, BeforeEachHook, AfterEachHook, AfterAllHook:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+package file
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Validate_ReturnsNoProblems_InCaseOfValidDirectory(t *testing.T) {
+	// Act
+	problems, err := Validate(filepath.Join("..", "testdata"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func Test_ValidateFS_ReturnsProblems_InCaseOfInvalidFiles(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_users.up.sql":    {Data: []byte("create table users(id int);")},
+		"1_create_users.down.sql":  {Data: []byte("drop table users;")},
+		"1_create_orders.up.sql":   {Data: []byte("create table orders(id int);")},
+		"2_add_email.up.sql":       {Data: []byte("alter table users add email text;")},
+		"3_add_phone.down.sql":     {Data: []byte(" \n")},
+		"4_add_name.UP.sql":        {Data: []byte("select 1;")},
+		"4_add_name.up.sq":         {Data: []byte("select 1;")},
+		"add_age.up.sql":           {Data: []byte("select 1;")},
+		"5a_add_age.down.sql":      {Data: []byte("select 1;")},
+		"6.up.sql":                 {Data: []byte("select 1;")},
+		"7_add_city.up.sql.bak":    {Data: []byte("select 1;")},
+		"structure.sql":            {Data: []byte("create table users(id int);")},
+		"R_views.sql":              {Data: []byte("create or replace view v as select 1;")},
+		"_before_each.sql":         {Data: []byte("")},
+		"6_backfill.go":            {Data: []byte("package migrations")},
+		"README.md":                {Data: []byte("# migrations")},
+		".hidden.up.sql":           {Data: []byte("")},
+		"archive/7_old.up.sql.bak": {Data: []byte("")},
+	}
+
+	// Act
+	problems, err := ValidateFS(fsys)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Problem{
+		{File: "1_create_users.up.sql", Kind: DuplicateProblem, Message: "version 1 is also used by 1_create_orders.up.sql"},
+		{File: "2_add_email.up.sql", Kind: UnpairedProblem, Message: "down migration of version 2 is missing"},
+		{File: "3_add_phone.down.sql", Kind: EmptyProblem, Message: "file is empty"},
+		{File: "3_add_phone.down.sql", Kind: UnpairedProblem, Message: "up migration of version 3 is missing"},
+		{File: "4_add_name.UP.sql", Kind: UnrecognisedProblem, Message: "expected a lower case .up.sql or .down.sql suffix"},
+		{File: "4_add_name.up.sq", Kind: UnrecognisedProblem, Message: "expected a lower case .up.sql or .down.sql suffix"},
+		{File: "5a_add_age.down.sql", Kind: StrayProblem, Message: "expected a numeric version prefix, for example 1494538273_create_table_users.up.sql"},
+		{File: "6.up.sql", Kind: UnrecognisedProblem, Message: "expected a <version>_<name> file name"},
+		{File: "7_add_city.up.sql.bak", Kind: UnrecognisedProblem, Message: "expected a lower case .up.sql or .down.sql suffix"},
+		{File: "_before_each.sql", Kind: EmptyProblem, Message: "file is empty"},
+		{File: "add_age.up.sql", Kind: StrayProblem, Message: "expected a numeric version prefix, for example 1494538273_create_table_users.up.sql"},
+		{File: "structure.sql", Kind: StrayProblem, Message: "not a migration, repeatable migration or hook file, it is ignored"},
+	}, problems)
+}
+
+func Test_Problem_Blocking_ReturnsFalse_InCaseOfStrayFile(t *testing.T) {
+	// Arrange
+	problem := Problem{File: "structure.sql", Kind: StrayProblem}
+
+	// Act
+	blocking := problem.Blocking()
+
+	// Assert
+	assert.False(t, blocking)
+}
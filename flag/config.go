@@ -0,0 +1,152 @@
+package flag
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the configuration file looked up in the working directory
+const DefaultConfigFile = "migrate.yaml"
+
+// Settings represents flag values of a configuration file
+type Settings struct {
+	URL                         string       `yaml:"url"`
+	Path                        string       `yaml:"path"`
+	Table                       string       `yaml:"table"`
+	Schema                      string       `yaml:"schema"`
+	TimeoutDuration             string       `yaml:"timeout-duration"`
+	RunTimeoutDuration          string       `yaml:"run-timeout-duration"`
+	MaxAttempts                 string       `yaml:"max-attempts"`
+	RetryDelayDuration          string       `yaml:"retry-delay-duration"`
+	DBConnectionTimeoutDuration string       `yaml:"db-conn-timeout-duration"`
+	LockTimeoutDuration         string       `yaml:"lock-timeout-duration"`
+	Lint                        LintSettings `yaml:"lint"`
+}
+
+// LintSettings represents the lint section of a configuration file
+type LintSettings struct {
+	Disable []string `yaml:"disable"`
+}
+
+// Config represents a configuration file with defaults and named environments
+type Config struct {
+	Settings     `yaml:",inline"`
+	Environments map[string]Settings `yaml:"environments"`
+}
+
+// ParseConfig parses a configuration file
+func ParseConfig(data []byte) (*Config, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, errors.Annotate(err, "decoding configuration failed")
+	}
+
+	return &config, nil
+}
+
+// LoadConfig reads the configuration file given with --config or found in the working directory
+// and makes the settings of the environment given with --env available to Get
+func LoadConfig(c *cli.Context) error {
+	path := c.GlobalString(ConfigFile)
+	env := c.GlobalString(Env)
+
+	if path == "" {
+		if _, err := os.Stat(DefaultConfigFile); err != nil {
+			if env != "" {
+				return errors.Errorf("environment %s requires a configuration file", env)
+			}
+
+			return nil
+		}
+
+		path = DefaultConfigFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Annotate(err, "reading configuration file failed")
+	}
+
+	config, err := ParseConfig(data)
+	if err != nil {
+		return errors.Annotatef(err, "parsing configuration file %s failed", path)
+	}
+
+	settings, err := config.Resolve(env)
+	if err != nil {
+		return err
+	}
+
+	if c.App.Metadata == nil {
+		c.App.Metadata = make(map[string]interface{})
+	}
+
+	c.App.Metadata[settingsKey] = settings
+
+	return nil
+}
+
+// Resolve returns flag values of the given environment on top of the defaults
+func (config *Config) Resolve(env string) (map[string]string, error) {
+	settings := config.Settings.values()
+	if env == "" {
+		return settings, nil
+	}
+
+	environment, ok := config.Environments[env]
+	if !ok {
+		return nil, errors.Errorf("environment %s not found in configuration file", env)
+	}
+
+	for name, value := range environment.values() {
+		settings[name] = value
+	}
+
+	return settings, nil
+}
+
+// private
+
+const settingsKey = "settings"
+
+func (s Settings) values() map[string]string {
+	values := map[string]string{
+		URL:                         s.URL,
+		Path:                        s.Path,
+		Table:                       s.Table,
+		Schema:                      s.Schema,
+		TimeoutDuration:             s.TimeoutDuration,
+		RunTimeoutDuration:          s.RunTimeoutDuration,
+		MaxAttempts:                 s.MaxAttempts,
+		RetryDelayDuration:          s.RetryDelayDuration,
+		DBConnectionTimeoutDuration: s.DBConnectionTimeoutDuration,
+		LockTimeoutDuration:         s.LockTimeoutDuration,
+		LintDisable:                 strings.Join(s.Lint.Disable, ","),
+	}
+
+	for name, value := range values {
+		if value == "" {
+			delete(values, name)
+		}
+	}
+
+	return values
+}
+
+func configValue(c *cli.Context, name string) string {
+	if c.App == nil {
+		return ""
+	}
+
+	settings, _ := c.App.Metadata[settingsKey].(map[string]string)
+
+	return settings[name]
+}
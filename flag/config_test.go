@@ -0,0 +1,117 @@
+package flag
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+const testConfig = `
+path: ./db/migrations
+timeout-duration: 10s
+environments:
+  dev:
+    url: postgres://user@localhost:5432/dev
+  prod:
+    url: postgres://user@prod:5432/prod
+    table: prod_migrations
+    timeout-duration: 1m
+`
+
+func Test_Config_Resolve_ReturnsEnvironmentOnTopOfDefaults(t *testing.T) {
+	// Arrange
+	config, err := ParseConfig([]byte(testConfig))
+	require.NoError(t, err)
+
+	// Act
+	settings, err := config.Resolve("prod")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		URL:             "postgres://user@prod:5432/prod",
+		Path:            "./db/migrations",
+		Table:           "prod_migrations",
+		TimeoutDuration: "1m",
+	}, settings)
+}
+
+func Test_Config_Resolve_ReturnsDefaults_InCaseOfNoEnvironment(t *testing.T) {
+	// Arrange
+	config, err := ParseConfig([]byte(testConfig))
+	require.NoError(t, err)
+
+	// Act
+	settings, err := config.Resolve("")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		Path:            "./db/migrations",
+		TimeoutDuration: "10s",
+	}, settings)
+}
+
+func Test_Config_Resolve_ReturnsError_InCaseOfUnknownEnvironment(t *testing.T) {
+	// Arrange
+	config, err := ParseConfig([]byte(testConfig))
+	require.NoError(t, err)
+
+	// Act
+	settings, err := config.Resolve("staging")
+
+	// Assert
+	assert.EqualError(t, err, "environment staging not found in configuration file")
+	assert.Nil(t, settings)
+}
+
+func Test_Config_Resolve_ReturnsDisabledLintRules_InCaseOfLintSection(t *testing.T) {
+	// Arrange
+	config, err := ParseConfig([]byte("lint:\n  disable: [drop-column, missing-down]\n"))
+	require.NoError(t, err)
+
+	// Act
+	settings, err := config.Resolve("")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{LintDisable: "drop-column,missing-down"}, settings)
+}
+
+func Test_ParseConfig_ReturnsError_InCaseOfUnknownField(t *testing.T) {
+	// Act
+	config, err := ParseConfig([]byte("uri: postgres://user@localhost:5432/dev\n"))
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, config)
+}
+
+func Test_Get_ReturnsConfigValue_InCaseOfFlagNotSet(t *testing.T) {
+	// Arrange
+	set := flag.NewFlagSet("test", 0)
+	set.String(URL, "", "")
+	set.String(Path, "", "")
+	require.NoError(t, set.Parse([]string{"--path", "./other"}))
+
+	app := cli.NewApp()
+	app.Metadata = map[string]interface{}{
+		settingsKey: map[string]string{
+			URL:  "postgres://user@localhost:5432/dev",
+			Path: "./db/migrations",
+		},
+	}
+
+	c := cli.NewContext(app, set, nil)
+
+	// Act
+	url := Get(c, URL)
+	path := Get(c, Path)
+
+	// Assert
+	assert.Equal(t, "postgres://user@localhost:5432/dev", url)
+	assert.Equal(t, "./other", path)
+}
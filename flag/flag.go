@@ -1,34 +1,101 @@
 package flag
 
 import (
+	"os"
+
 	"github.com/juju/errors"
 	"github.com/urfave/cli"
 )
 
 const (
-	// URL represents database URL.
+	// URL represents database URL, it may be repeated by up and down.
 	URL = "url"
+	// URLEnvVar is the environment variable of URL.
+	URLEnvVar = "MIGRATE_URL"
 	// Path represents migrations path.
 	Path = "path"
 	// Timeout represents execution timeout in seconds. Default value: 1s.
 	// Deprecated: use --timeout-duration instead.
 	Timeout = "timeout"
-	// TimeoutDuration represents execution timeout in duration.
+	// TimeoutDuration represents execution timeout of every migration file and query in duration,
+	// migration files can override it with the timeout directive.
 	// TimeoutDuration will override timeout setting. Default value: 1s.
 	TimeoutDuration = "timeout-duration"
+	// RunTimeoutDuration represents the time budget of a whole migration run in duration. Default value: unlimited.
+	RunTimeoutDuration = "run-timeout-duration"
+	// MaxAttempts represents the number of attempts of a migration that failed with a lock timeout,
+	// deadlock or serialization conflict. Default value: 1.
+	MaxAttempts = "max-attempts"
+	// RetryDelayDuration represents the delay before the first retry in duration, doubled on every attempt.
+	// Default value: 1s.
+	RetryDelayDuration = "retry-delay-duration"
 	// DBConnectionTimeoutDuration represents database connection timeout in duration. Default value: 1s.
 	DBConnectionTimeoutDuration = "db-conn-timeout-duration"
+	// LockTimeoutDuration represents migration lock wait timeout in duration. Default value: 1m.
+	LockTimeoutDuration = "lock-timeout-duration"
 	// NoVerify skips verification of already migrated older migrations.
 	NoVerify = "no-verify"
+	// NoValidate skips validation of the migration files before migrating up or down.
+	NoValidate = "no-validate"
+	// NoChecksum skips checksum verification of already migrated migrations.
+	NoChecksum = "no-checksum"
 	// Verbose enables verbose output.
 	Verbose = "verbose"
+	// DryRun prints migrations that would be applied without applying them.
+	DryRun = "dry-run"
+	// Quiet prints only migration file names in dry run.
+	Quiet = "quiet"
+	// Output represents output file path.
+	Output = "output"
+	// Format represents output format, text, json or github. Default value: text.
+	Format = "format"
+	// ConfigFile represents configuration file path. Default value: migrate.yaml if it exists.
+	ConfigFile = "config"
+	// Env represents the configuration file environment to use.
+	Env = "env"
+	// Table represents migrations table name. Default value: schema_migrations.
+	Table = "table"
+	// Schema represents migrations table schema. Default value: current schema.
+	Schema = "schema"
+	// Schemas represents a comma separated list of schemas to migrate one by one.
+	Schemas = "schemas"
+	// SchemaPattern represents a LIKE pattern of schemas to migrate one by one.
+	SchemaPattern = "schema-pattern"
+	// ContinueOnError continues migrating the remaining schemas after a schema failed.
+	ContinueOnError = "continue-on-error"
+	// URLsFile represents a file with one database URL per line.
+	URLsFile = "urls-file"
+	// Concurrency represents the number of databases migrated at the same time. Default value: 4.
+	Concurrency = "concurrency"
+	// LintDisable represents the comma separated lint rules that are not checked.
+	LintDisable = "lint-disable"
+	// Since represents the date or time from which the migration history is listed.
+	Since = "since"
+	// Until represents the date or time until which the migration history is listed, exclusive.
+	Until = "until"
 )
 
 var Flags = map[string]cli.Flag{
-	URL: cli.StringFlag{
-		Name:   URL,
-		Usage:  "database URL, for example postgres://user@host:port/database",
-		EnvVar: "MIGRATE_URL",
+	// The environment variable of URL is resolved by GetStrings, so that it is never split on commas
+	// and command line values take precedence over it
+	URL: cli.StringSliceFlag{
+		Name:  URL,
+		Usage: "database URL, for example postgres://user@host:port/database, up and down accept it repeatedly [$" + URLEnvVar + "]",
+	},
+	URLsFile: cli.StringFlag{
+		Name:   URLsFile,
+		Usage:  "file with one database URL per line to migrate in parallel",
+		EnvVar: "MIGRATE_URLS_FILE",
+	},
+	Concurrency: cli.IntFlag{
+		Name:   Concurrency,
+		Usage:  "number of databases migrated at the same time, defaults to 4",
+		EnvVar: "MIGRATE_CONCURRENCY",
+	},
+	LintDisable: cli.StringFlag{
+		Name:   LintDisable,
+		Usage:  "comma separated lint rules that are not checked",
+		EnvVar: "MIGRATE_LINT_DISABLE",
 	},
 	Path: cli.StringFlag{
 		Name:   Path,
@@ -46,6 +113,16 @@ var Flags = map[string]cli.Flag{
 		Usage:  "skip verification of already migrated older migrations",
 		EnvVar: "MIGRATE_NO_VERIFY",
 	},
+	NoValidate: cli.BoolFlag{
+		Name:   NoValidate,
+		Usage:  "skip validation of the migration files before migrating up or down",
+		EnvVar: "MIGRATE_NO_VALIDATE",
+	},
+	NoChecksum: cli.BoolFlag{
+		Name:   NoChecksum,
+		Usage:  "skip checksum verification of already migrated migrations",
+		EnvVar: "MIGRATE_NO_CHECKSUM",
+	},
 	Verbose: cli.BoolFlag{
 		Name:   Verbose,
 		Usage:  "enable verbose output",
@@ -53,17 +130,99 @@ var Flags = map[string]cli.Flag{
 	},
 	TimeoutDuration: cli.DurationFlag{
 		Name:   TimeoutDuration,
-		Usage:  "execution timeout in duration, defaults to 1 second",
+		Usage:  "execution timeout of every migration file in duration, defaults to 1 second",
 		EnvVar: "MIGRATE_TIMEOUT_DURATION",
 	},
+	RunTimeoutDuration: cli.DurationFlag{
+		Name:   RunTimeoutDuration,
+		Usage:  "time budget of the whole migration run in duration, unlimited by default",
+		EnvVar: "MIGRATE_RUN_TIMEOUT_DURATION",
+	},
+	MaxAttempts: cli.IntFlag{
+		Name:   MaxAttempts,
+		Usage:  "number of attempts of a migration failing with a lock timeout, deadlock or serialization conflict, defaults to 1",
+		EnvVar: "MIGRATE_MAX_ATTEMPTS",
+	},
+	RetryDelayDuration: cli.DurationFlag{
+		Name:   RetryDelayDuration,
+		Usage:  "delay before the first retry in duration, doubled on every attempt, defaults to 1 second",
+		EnvVar: "MIGRATE_RETRY_DELAY_DURATION",
+	},
 	DBConnectionTimeoutDuration: cli.DurationFlag{
 		Name:   DBConnectionTimeoutDuration,
 		Usage:  "database connection timeout in duration, defaults to 1 second",
 		EnvVar: "MIGRATE_DB_CONN_TIMEOUT_DURATION",
 	},
+	DryRun: cli.BoolFlag{
+		Name:   DryRun,
+		Usage:  "print migrations and their SQL that would be applied without applying them",
+		EnvVar: "MIGRATE_DRY_RUN",
+	},
+	Quiet: cli.BoolFlag{
+		Name:   Quiet,
+		Usage:  "print only migration file names in dry run",
+		EnvVar: "MIGRATE_QUIET",
+	},
+	Since: cli.StringFlag{
+		Name:  Since,
+		Usage: "list history of migrations started at or after the date (2006-01-02) or time (RFC 3339)",
+	},
+	Until: cli.StringFlag{
+		Name:  Until,
+		Usage: "list history of migrations started before the time (RFC 3339) or until the end of the date (2006-01-02)",
+	},
+	Output: cli.StringFlag{
+		Name:  Output,
+		Usage: "output file, defaults to standard output",
+	},
+	Format: cli.StringFlag{
+		Name:   Format,
+		Usage:  "output format, text, json or github (lint findings as GitHub Actions annotations), defaults to text",
+		EnvVar: "MIGRATE_FORMAT",
+	},
+	Table: cli.StringFlag{
+		Name:   Table,
+		Usage:  "migrations table name, defaults to schema_migrations",
+		EnvVar: "MIGRATE_TABLE",
+	},
+	Schema: cli.StringFlag{
+		Name:   Schema,
+		Usage:  "migrations table schema, defaults to the current schema",
+		EnvVar: "MIGRATE_SCHEMA",
+	},
+	Schemas: cli.StringFlag{
+		Name:   Schemas,
+		Usage:  "comma separated schemas to migrate one by one with search_path set to each of them",
+		EnvVar: "MIGRATE_SCHEMAS",
+	},
+	SchemaPattern: cli.StringFlag{
+		Name:   SchemaPattern,
+		Usage:  "LIKE pattern of schemas to migrate one by one, for example tenant_%",
+		EnvVar: "MIGRATE_SCHEMA_PATTERN",
+	},
+	ContinueOnError: cli.BoolFlag{
+		Name:   ContinueOnError,
+		Usage:  "continue migrating the remaining schemas after a schema failed",
+		EnvVar: "MIGRATE_CONTINUE_ON_ERROR",
+	},
+	ConfigFile: cli.StringFlag{
+		Name:   ConfigFile,
+		Usage:  "configuration file, defaults to migrate.yaml in current working directory",
+		EnvVar: "MIGRATE_CONFIG",
+	},
+	Env: cli.StringFlag{
+		Name:   Env,
+		Usage:  "configuration file environment, for example dev, staging or prod",
+		EnvVar: "MIGRATE_ENV",
+	},
+	LockTimeoutDuration: cli.DurationFlag{
+		Name:   LockTimeoutDuration,
+		Usage:  "migration lock wait timeout in duration, defaults to 1 minute",
+		EnvVar: "MIGRATE_LOCK_TIMEOUT_DURATION",
+	},
 }
 
-// Get returns a flag value.
+// Get returns a flag value, falling back to the loaded configuration file.
 func Get(c *cli.Context, name string) string {
 	value := ""
 	if c.IsSet(name) {
@@ -74,9 +233,35 @@ func Get(c *cli.Context, name string) string {
 		value = c.GlobalString(name)
 	}
 
+	if value == "" {
+		value = configValue(c, name)
+	}
+
 	return value
 }
 
+// GetStrings returns values of a flag that may be repeated on the command line.
+// Command flags take precedence over global flags, the environment variable and the loaded
+// configuration file, the latter two are returned as a single value.
+func GetStrings(c *cli.Context, name string) []string {
+	switch {
+	case c.IsSet(name):
+		return stringValues(c.StringSlice(name), c.String(name))
+	case c.GlobalIsSet(name):
+		return stringValues(c.GlobalStringSlice(name), c.GlobalString(name))
+	}
+
+	if value := os.Getenv(envVars[name]); value != "" {
+		return []string{value}
+	}
+
+	if value := configValue(c, name); value != "" {
+		return []string{value}
+	}
+
+	return nil
+}
+
 // GetBool returns a boolean flag value.
 func GetBool(c *cli.Context, name string) bool {
 	return c.Bool(name) || c.GlobalBool(name)
@@ -91,3 +276,23 @@ func NewRequiredFlagError(name string) error {
 func NewWrongFormatFlagError(name string) error {
 	return errors.New("parsing " + name + " failed")
 }
+
+// private
+
+// envVars are the environment variables of repeatable flags
+var envVars = map[string]string{
+	URL: URLEnvVar,
+}
+
+// stringValues returns the values of a repeatable flag or the value of a flag defined as a string
+func stringValues(values []string, value string) []string {
+	if len(values) > 0 {
+		return values
+	}
+
+	if value != "" {
+		return []string{value}
+	}
+
+	return nil
+}
@@ -0,0 +1,254 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wallester/migrate/file"
+)
+
+const (
+	// DropTable warns about DROP TABLE in up migrations.
+	DropTable = "drop-table"
+	// DropColumn warns about dropping columns in up migrations.
+	DropColumn = "drop-column"
+	// AlterColumnType warns about changing the type of a column, which usually rewrites the table.
+	AlterColumnType = "alter-column-type"
+	// NotNullWithoutDefault warns about adding a NOT NULL column without a default,
+	// which fails on non-empty tables.
+	NotNullWithoutDefault = "not-null-without-default"
+	// CreateIndexNotConcurrently warns about CREATE INDEX without CONCURRENTLY on a table
+	// that is not created in the same file, which blocks writes to the table.
+	CreateIndexNotConcurrently = "create-index-not-concurrently"
+	// MissingDown warns about up migrations without a down migration.
+	MissingDown = "missing-down"
+	// EmptyDown warns about down migrations without statements.
+	EmptyDown = "empty-down"
+	// TransactionStatement warns about BEGIN, COMMIT and ROLLBACK in files that already run in a transaction.
+	TransactionStatement = "transaction-statement"
+)
+
+// Rules lists the names of all rules
+var Rules = []string{
+	DropTable,
+	DropColumn,
+	AlterColumnType,
+	NotNullWithoutDefault,
+	CreateIndexNotConcurrently,
+	MissingDown,
+	EmptyDown,
+	TransactionStatement,
+}
+
+// IgnoreDirective disables the given rules, for example -- migrate:lint-ignore drop-column.
+// In the header comment it applies to the whole file, elsewhere to the statement it precedes or is in.
+const IgnoreDirective = "-- migrate:lint-ignore"
+
+// Finding represents a risky pattern found in a migration file.
+// Line is 0 for findings about the whole file.
+type Finding struct {
+	File    string
+	Line    int
+	Rule    string
+	Message string
+}
+
+// String returns the finding in the file:line: rule: message format, without the line for the whole file
+func (f Finding) String() string {
+	if f.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", f.File, f.Rule, f.Message)
+	}
+
+	return fmt.Sprintf("%s:%d: %s: %s", f.File, f.Line, f.Rule, f.Message)
+}
+
+// Lint checks SQL migration files for risky patterns, skipping the disabled rules.
+// Findings are sorted by file and line.
+func Lint(upFiles, downFiles []file.File, disabled []string) ([]Finding, error) {
+	skip := make(map[string]bool, len(disabled))
+	for _, rule := range disabled {
+		if !isRule(rule) {
+			return nil, fmt.Errorf("unknown lint rule %q", rule)
+		}
+
+		skip[rule] = true
+	}
+
+	downs := make(map[int64]file.File, len(downFiles))
+	for _, f := range downFiles {
+		downs[f.Version] = f
+	}
+
+	var findings []Finding
+	for _, f := range upFiles {
+		if f.Func != nil {
+			continue
+		}
+
+		statements, ignored := split(f.SQL)
+		findings = append(findings, lintStatements(f, statements, true)...)
+
+		if _, ok := downs[f.Version]; !ok && !ignored[MissingDown] {
+			findings = append(findings, Finding{File: f.Base, Rule: MissingDown, Message: "up migration has no down migration"})
+		}
+	}
+
+	for _, f := range downFiles {
+		if f.Func != nil {
+			continue
+		}
+
+		statements, ignored := split(f.SQL)
+		findings = append(findings, lintStatements(f, statements, false)...)
+
+		if len(statements) == 0 && !ignored[EmptyDown] {
+			findings = append(findings, Finding{File: f.Base, Rule: EmptyDown, Message: "down migration has no statements"})
+		}
+	}
+
+	enabled := findings[:0]
+	for _, finding := range findings {
+		if !skip[finding.Rule] {
+			enabled = append(enabled, finding)
+		}
+	}
+
+	sort.SliceStable(enabled, func(i, j int) bool {
+		if enabled[i].File != enabled[j].File {
+			return enabled[i].File < enabled[j].File
+		}
+
+		return enabled[i].Line < enabled[j].Line
+	})
+
+	return enabled, nil
+}
+
+// private
+
+// identifier matches a possibly quoted and schema qualified identifier
+const identifier = `((?:"[^"]*"|\w+)(?:\.(?:"[^"]*"|\w+))?)`
+
+var (
+	dropTablePattern       = regexp.MustCompile(`(?i)^DROP TABLE\b`)
+	alterTablePattern      = regexp.MustCompile(`(?i)^ALTER TABLE (IF EXISTS )?(ONLY )?` + identifier + ` ?`)
+	dropPattern            = regexp.MustCompile(`(?i)^DROP (COLUMN )?(IF EXISTS )?("[^"]*"|\w+)`)
+	alterColumnTypePattern = regexp.MustCompile(`(?i)^ALTER (COLUMN )?("[^"]*"|\w+) (SET DATA )?TYPE\b`)
+	addPattern             = regexp.MustCompile(`(?i)^ADD (COLUMN )?(IF NOT EXISTS )?("[^"]*"|\w+)`)
+	notNullPattern         = regexp.MustCompile(`(?i)\bNOT NULL\b`)
+	defaultPattern         = regexp.MustCompile(`(?i)\b(DEFAULT|GENERATED)\b`)
+	createTablePattern     = regexp.MustCompile(`(?i)^CREATE (UNLOGGED |TEMP |TEMPORARY )?TABLE (IF NOT EXISTS )?` + identifier)
+	createIndexPattern     = regexp.MustCompile(`(?i)^CREATE (UNIQUE )?INDEX (CONCURRENTLY )?.*?\bON (ONLY )?` + identifier)
+	transactionPattern     = regexp.MustCompile(`(?i)^(BEGIN|START TRANSACTION|COMMIT|END|ROLLBACK|ABORT)\b`)
+	rollbackToPattern      = regexp.MustCompile(`(?i)^ROLLBACK (WORK |TRANSACTION )?TO\b`)
+)
+
+// constraintKeywords start actions that add or drop constraints rather than columns
+var constraintKeywords = map[string]bool{
+	"CONSTRAINT": true,
+	"PRIMARY":    true,
+	"UNIQUE":     true,
+	"FOREIGN":    true,
+	"CHECK":      true,
+	"EXCLUDE":    true,
+}
+
+func isRule(name string) bool {
+	for _, rule := range Rules {
+		if rule == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lintStatements checks the statements of a file
+func lintStatements(f file.File, statements []statement, up bool) []Finding {
+	createdTables := make(map[string]bool)
+	for _, s := range statements {
+		if m := createTablePattern.FindStringSubmatch(s.sql); m != nil {
+			createdTables[tableName(m[3])] = true
+		}
+	}
+
+	var findings []Finding
+	report := func(s statement, rule, message string) {
+		if !s.ignored[rule] {
+			findings = append(findings, Finding{File: f.Base, Line: s.line, Rule: rule, Message: message})
+		}
+	}
+
+	for _, s := range statements {
+		if up && dropTablePattern.MatchString(s.sql) {
+			report(s, DropTable, "dropping a table in an up migration loses data")
+		}
+
+		if alterTablePattern.MatchString(s.sql) {
+			for _, action := range actions(s.sql) {
+				if up {
+					if m := dropPattern.FindStringSubmatch(action); m != nil && (m[1] != "" || !constraintKeywords[strings.ToUpper(m[3])]) {
+						report(s, DropColumn, fmt.Sprintf("dropping column %s in an up migration loses data", m[3]))
+					}
+				}
+
+				if m := alterColumnTypePattern.FindStringSubmatch(action); m != nil {
+					report(s, AlterColumnType, fmt.Sprintf("changing the type of column %s may rewrite the table", m[2]))
+				}
+
+				if m := addPattern.FindStringSubmatch(action); m != nil && !constraintKeywords[strings.ToUpper(m[3])] &&
+					notNullPattern.MatchString(action) && !defaultPattern.MatchString(action) {
+					report(s, NotNullWithoutDefault, fmt.Sprintf("adding NOT NULL column %s without a default fails on non-empty tables", m[3]))
+				}
+			}
+		}
+
+		if m := createIndexPattern.FindStringSubmatch(s.sql); m != nil && m[2] == "" && !createdTables[tableName(m[4])] {
+			report(s, CreateIndexNotConcurrently, fmt.Sprintf("creating an index without CONCURRENTLY blocks writes to %s", m[4]))
+		}
+
+		if !f.NoTransaction && transactionPattern.MatchString(s.sql) && !rollbackToPattern.MatchString(s.sql) {
+			report(s, TransactionStatement, "the file already runs in a transaction, use the no-transaction directive to control transactions")
+		}
+	}
+
+	return findings
+}
+
+// actions splits an ALTER TABLE statement into its comma separated actions
+func actions(sql string) []string {
+	sql = sql[len(alterTablePattern.FindString(sql)):]
+
+	var (
+		result []string
+		depth  int
+		start  int
+	)
+
+	for i, r := range sql {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, strings.TrimSpace(sql[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return append(result, strings.TrimSpace(sql[start:]))
+}
+
+// tableName normalizes a table name for comparison, ignoring the schema
+func tableName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return strings.ToLower(strings.Trim(name, `"`))
+}
@@ -0,0 +1,163 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wallester/migrate/file"
+)
+
+func Test_Lint_ReturnsFindings_InCaseOfRiskyUpMigration(t *testing.T) {
+	// Arrange
+	up := []file.File{
+		{
+			Base:    "1_risky.up.sql",
+			Version: 1,
+			SQL: "drop table logs;\n" +
+				"alter table users\n" +
+				"  drop column email,\n" +
+				"  alter column name type text,\n" +
+				"  add column age int not null;\n" +
+				"create index users_name_idx on users(name);\n",
+		},
+	}
+	down := []file.File{
+		{Base: "1_risky.down.sql", Version: 1, SQL: "create table logs(id int);"},
+	}
+
+	// Act
+	findings, err := Lint(up, down, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{File: "1_risky.up.sql", Line: 1, Rule: DropTable, Message: "dropping a table in an up migration loses data"},
+		{File: "1_risky.up.sql", Line: 2, Rule: DropColumn, Message: "dropping column email in an up migration loses data"},
+		{File: "1_risky.up.sql", Line: 2, Rule: AlterColumnType, Message: "changing the type of column name may rewrite the table"},
+		{File: "1_risky.up.sql", Line: 2, Rule: NotNullWithoutDefault, Message: "adding NOT NULL column age without a default fails on non-empty tables"},
+		{File: "1_risky.up.sql", Line: 6, Rule: CreateIndexNotConcurrently, Message: "creating an index without CONCURRENTLY blocks writes to users"},
+	}, findings)
+}
+
+func Test_Lint_ReturnsNoFindings_InCaseOfSafeMigrations(t *testing.T) {
+	// Arrange
+	up := []file.File{
+		{
+			Base:    "1_create_table.up.sql",
+			Version: 1,
+			SQL: "-- creates users\n" +
+				"create table users(id int not null, name text);\n" +
+				"create index users_name_idx on users(name);\n" +
+				"alter table users add column age int not null default 0, drop constraint users_pkey;\n" +
+				"create function f() returns void as $body$ begin; commit; end $body$ language plpgsql;\n" +
+				"insert into users(name) values('drop table users; begin');\n",
+		},
+		{
+			Base:          "2_create_index.up.sql",
+			Version:       2,
+			SQL:           "-- migrate:no-transaction\ncreate index concurrently users_age_idx on users(age);\n",
+			NoTransaction: true,
+		},
+	}
+	down := []file.File{
+		{Base: "1_create_table.down.sql", Version: 1, SQL: "drop table users;"},
+		{Base: "2_create_index.down.sql", Version: 2, SQL: "drop index concurrently users_age_idx;"},
+	}
+
+	// Act
+	findings, err := Lint(up, down, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func Test_Lint_ReturnsFindings_InCaseOfMissingOrEmptyDownMigration(t *testing.T) {
+	// Arrange
+	up := []file.File{
+		{Base: "1_a.up.sql", Version: 1, SQL: "select 1;"},
+		{Base: "2_b.up.sql", Version: 2, SQL: "select 2;"},
+	}
+	down := []file.File{
+		{Base: "2_b.down.sql", Version: 2, SQL: "-- nothing to do\n"},
+	}
+
+	// Act
+	findings, err := Lint(up, down, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{File: "1_a.up.sql", Rule: MissingDown, Message: "up migration has no down migration"},
+		{File: "2_b.down.sql", Rule: EmptyDown, Message: "down migration has no statements"},
+	}, findings)
+}
+
+func Test_Lint_ReturnsFinding_InCaseOfTransactionStatement(t *testing.T) {
+	// Arrange
+	up := []file.File{
+		{Base: "1_a.up.sql", Version: 1, SQL: "BEGIN;\nselect 1;\nCOMMIT;\n"},
+	}
+	down := []file.File{
+		{Base: "1_a.down.sql", Version: 1, SQL: "select 1;"},
+	}
+
+	// Act
+	findings, err := Lint(up, down, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, findings, 2) {
+		assert.Equal(t, TransactionStatement, findings[0].Rule)
+		assert.Equal(t, 1, findings[0].Line)
+		assert.Equal(t, 3, findings[1].Line)
+	}
+}
+
+func Test_Lint_SkipsIgnoredRules_InCaseOfIgnoreDirectives(t *testing.T) {
+	// Arrange
+	up := []file.File{
+		{
+			Base:    "1_a.up.sql",
+			Version: 1,
+			SQL: "-- migrate:lint-ignore missing-down, create-index-not-concurrently\n" +
+				"create index users_name_idx on users(name);\n" +
+				"drop table logs; -- migrate:lint-ignore drop-table\n" +
+				"-- migrate:lint-ignore drop-column\n" +
+				"alter table users drop column email;\n" +
+				"alter table users drop column name;\n",
+		},
+	}
+
+	// Act
+	findings, err := Lint(up, nil, nil)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{File: "1_a.up.sql", Line: 6, Rule: DropColumn, Message: "dropping column name in an up migration loses data"},
+	}, findings)
+}
+
+func Test_Lint_SkipsDisabledRules_InCaseOfDisabledRules(t *testing.T) {
+	// Arrange
+	up := []file.File{
+		{Base: "1_a.up.sql", Version: 1, SQL: "drop table logs;"},
+	}
+
+	// Act
+	findings, err := Lint(up, nil, []string{DropTable, MissingDown})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func Test_Lint_ReturnsError_InCaseOfUnknownDisabledRule(t *testing.T) {
+	// Act
+	findings, err := Lint(nil, nil, []string{"drop-everything"})
+
+	// Assert
+	assert.EqualError(t, err, `unknown lint rule "drop-everything"`)
+	assert.Nil(t, findings)
+}
@@ -0,0 +1,158 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// private
+
+// statement represents a SQL statement whose comments, string literals and dollar quoted bodies
+// are blanked out and whose whitespace is collapsed
+type statement struct {
+	line    int
+	sql     string
+	ignored map[string]bool
+}
+
+var (
+	whitespacePattern = regexp.MustCompile(`\s+`)
+	dollarTagPattern  = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+)
+
+// split splits the SQL of a migration file into statements and returns the rules ignored in its header comment.
+// Rules ignored in the header are ignored in every statement too, other ignore directives apply to
+// the statement they are in, follow on the same line or precede.
+func split(sql string) ([]statement, map[string]bool) {
+	var (
+		statements  []statement
+		current     strings.Builder
+		line        = 1
+		startLine   int
+		endLine     int
+		ignored     = make(map[string]bool)
+		fileIgnored = make(map[string]bool)
+		header      = true
+	)
+
+	write := func(s string) {
+		if startLine == 0 && strings.TrimSpace(s) != "" {
+			startLine = line
+			header = false
+		}
+
+		current.WriteString(s)
+	}
+
+	flush := func() {
+		text := strings.TrimSpace(whitespacePattern.ReplaceAllString(current.String(), " "))
+		if text != "" {
+			statements = append(statements, statement{line: startLine, sql: text, ignored: ignored})
+			ignored = make(map[string]bool)
+			endLine = line
+		}
+
+		current.Reset()
+		startLine = 0
+	}
+
+	for i := 0; i < len(sql); {
+		end := i + 1
+		switch c := sql[i]; {
+		case strings.HasPrefix(sql[i:], "--"):
+			end = len(sql)
+			if n := strings.IndexByte(sql[i:], '\n'); n >= 0 {
+				end = i + n
+			}
+
+			if rules, ok := ignoreRules(sql[i:end]); ok {
+				target := ignored
+				switch {
+				case header:
+					target = fileIgnored
+				case startLine == 0 && endLine == line:
+					// A comment after the end of a statement on the same line belongs to it
+					target = statements[len(statements)-1].ignored
+				}
+
+				for _, rule := range rules {
+					target[rule] = true
+				}
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			end = len(sql)
+			if n := strings.Index(sql[i+2:], "*/"); n >= 0 {
+				end = i + 2 + n + 2
+			}
+
+			write(" ")
+		case c == '\'':
+			end = closeQuote(sql, i, i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e'))
+			write("''")
+		case c == '"':
+			end = len(sql)
+			if n := strings.IndexByte(sql[i+1:], '"'); n >= 0 {
+				end = i + 1 + n + 1
+			}
+
+			write(sql[i:end])
+		case c == '$' && (i == 0 || !isIdentifierByte(sql[i-1])) && dollarTagPattern.MatchString(sql[i:]):
+			tag := dollarTagPattern.FindString(sql[i:])
+			end = len(sql)
+			if n := strings.Index(sql[i+len(tag):], tag); n >= 0 {
+				end = i + len(tag) + n + len(tag)
+			}
+
+			write("$$")
+		case c == ';':
+			flush()
+		default:
+			write(sql[i:end])
+		}
+
+		line += strings.Count(sql[i:end], "\n")
+		i = end
+	}
+
+	flush()
+
+	for _, s := range statements {
+		for rule := range fileIgnored {
+			s.ignored[rule] = true
+		}
+	}
+
+	return statements, fileIgnored
+}
+
+// ignoreRules returns the rules of an ignore directive comment
+func ignoreRules(comment string) ([]string, bool) {
+	comment = strings.TrimSpace(comment)
+	if !strings.HasPrefix(comment, IgnoreDirective+" ") {
+		return nil, false
+	}
+
+	return strings.FieldsFunc(strings.TrimPrefix(comment, IgnoreDirective), func(r rune) bool {
+		return r == ' ' || r == ','
+	}), true
+}
+
+// closeQuote returns the index after the string literal starting at i
+func closeQuote(sql string, i int, backslashEscapes bool) int {
+	for j := i + 1; j < len(sql); j++ {
+		switch {
+		case backslashEscapes && sql[j] == '\\':
+			j++
+		case sql[j] == '\'' && j+1 < len(sql) && sql[j+1] == '\'':
+			j++
+		case sql[j] == '\'':
+			return j + 1
+		}
+	}
+
+	return len(sql)
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/wallester/migrate/app"
 )
 
 func main() {
-	if err := app.New().Run(os.Args); err != nil {
-		//nolint:forbidigo
-		fmt.Println(err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.NewWithContext(ctx).Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		stop()
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,254 @@
+// Package migrate runs migrations from Go code.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/driver/postgres"
+	"github.com/wallester/migrate/migrator"
+	"github.com/wallester/migrate/printer"
+)
+
+// Result represents a result of a migration run
+type Result = migrator.Result
+
+// Migration represents a migrated file
+type Migration = migrator.Migration
+
+// Status represents a status of a migration version
+type Status = migrator.Status
+
+// Statuses represents a list of migration statuses
+type Statuses = migrator.Statuses
+
+// State represents a state of a migration version
+type State = migrator.State
+
+// States of migration versions
+const (
+	Applied  = migrator.Applied
+	Pending  = migrator.Pending
+	Orphaned = migrator.Orphaned
+	Dirty    = migrator.Dirty
+)
+
+// Register registers Go functions that migrate the version up and down within the transaction of the migration,
+// down may be nil. Call it from init functions of the files that contain the migrations, they are run
+// in version order together with the SQL files and recorded like them.
+var Register = migrator.Register
+
+// Client runs migrations
+type Client struct {
+	migrator *migrator.Migrator
+	args     migrator.Args
+}
+
+// Option configures a Client
+type Option func(*options)
+
+// WithDB uses an already open database connection, which is left open
+func WithDB(db *sql.DB) Option {
+	return func(o *options) {
+		o.db = db
+	}
+}
+
+// WithURL opens a new database connection for every run
+func WithURL(url string) Option {
+	return func(o *options) {
+		o.args.URL = url
+	}
+}
+
+// WithFS reads migration files from the root of the given file system
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// WithPath reads migration files from the given directory
+func WithPath(path string) Option {
+	return func(o *options) {
+		o.args.Path = path
+	}
+}
+
+// WithPrinter prints progress to the given printer, output is discarded by default
+func WithPrinter(output printer.IPrinter) Option {
+	return func(o *options) {
+		o.output = output
+	}
+}
+
+// WithTimeout sets execution timeout of every migration file, defaults to 1 second.
+// Files can override it with the -- migrate:timeout directive.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.args.TimeoutDuration = timeout
+	}
+}
+
+// WithRunTimeout sets the time budget of a whole run, unlimited by default
+func WithRunTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.args.RunTimeoutDuration = timeout
+	}
+}
+
+// WithRetry retries the transaction of a migration failing with a lock timeout, deadlock or
+// serialization conflict up to maxAttempts attempts, waiting delay before the first retry and
+// doubling it with jitter on every attempt. Migrations are not retried by default.
+func WithRetry(maxAttempts int, delay time.Duration) Option {
+	return func(o *options) {
+		o.args.MaxAttempts = maxAttempts
+		o.args.RetryDelayDuration = delay
+	}
+}
+
+// WithConnectionTimeout sets database connection timeout, defaults to 1 second
+func WithConnectionTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.args.DBConnectionTimeoutDuration = timeout
+	}
+}
+
+// WithLockTimeout sets migration lock wait timeout, defaults to 1 minute
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.args.LockTimeoutDuration = timeout
+	}
+}
+
+// WithTable records migrations in the given table instead of schema_migrations,
+// an empty schema means the current schema
+func WithTable(schema, name string) Option {
+	return func(o *options) {
+		o.args.Schema = schema
+		o.args.Table = name
+	}
+}
+
+// WithNoVerify skips verification of already migrated older migrations
+func WithNoVerify() Option {
+	return func(o *options) {
+		o.args.NoVerify = true
+	}
+}
+
+// WithNoValidate skips validation of the migration files before migrating up or down
+func WithNoValidate() Option {
+	return func(o *options) {
+		o.args.NoValidate = true
+	}
+}
+
+// WithNoChecksum skips checksum verification of already migrated migrations
+func WithNoChecksum() Option {
+	return func(o *options) {
+		o.args.NoChecksum = true
+	}
+}
+
+// WithVerbose enables verbose output
+func WithVerbose() Option {
+	return func(o *options) {
+		o.args.Verbose = true
+	}
+}
+
+// New returns new instance
+func New(opts ...Option) (*Client, error) {
+	o := options{
+		output: &printer.Discard{},
+		args: migrator.Args{
+			TimeoutDuration:             time.Second,
+			DBConnectionTimeoutDuration: time.Second,
+			LockTimeoutDuration:         time.Minute,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.db == nil && o.args.URL == "" {
+		return nil, errors.New("please specify database connection or URL")
+	}
+
+	if o.fsys == nil && o.args.Path == "" {
+		return nil, errors.New("please specify migrations file system or path")
+	}
+
+	d := postgres.New()
+	if o.db != nil {
+		d = postgres.NewWithDB(o.db)
+	}
+
+	m := migrator.New(d, o.output)
+	if o.fsys != nil {
+		m = migrator.NewWithFS(d, o.output, o.fsys)
+	}
+
+	return &Client{
+		migrator: m,
+		args:     o.args,
+	}, nil
+}
+
+// Up applies all up migrations. When migrating several databases or schemas fails,
+// the result of all of them is returned together with the error.
+func (c *Client) Up(ctx context.Context) (*Result, error) {
+	args := c.args
+	args.Direction = direction.Up
+
+	result, err := c.migrator.MigrateContext(ctx, args)
+	if err != nil {
+		return result, errors.Annotate(err, "migrating up failed")
+	}
+
+	return result, nil
+}
+
+// Down applies <n> down migrations. When migrating several databases or schemas fails,
+// the result of all of them is returned together with the error.
+func (c *Client) Down(ctx context.Context, n int) (*Result, error) {
+	if n < 1 {
+		return nil, errors.New("please specify a positive number of down migrations")
+	}
+
+	args := c.args
+	args.Direction = direction.Down
+	args.Steps = n
+
+	result, err := c.migrator.MigrateContext(ctx, args)
+	if err != nil {
+		return result, errors.Annotate(err, "migrating down failed")
+	}
+
+	return result, nil
+}
+
+// Status returns applied, pending and orphaned migrations
+func (c *Client) Status(ctx context.Context) (Statuses, error) {
+	statuses, err := c.migrator.StatusContext(ctx, c.args)
+	if err != nil {
+		return nil, errors.Annotate(err, "getting migration status failed")
+	}
+
+	return statuses, nil
+}
+
+// private
+
+type options struct {
+	db     *sql.DB
+	fsys   fs.FS
+	output printer.IPrinter
+	args   migrator.Args
+}
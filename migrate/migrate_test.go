@@ -0,0 +1,89 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/migrator"
+	"github.com/wallester/migrate/printer"
+)
+
+func Test_New_ReturnsInstance_InCaseOfSuccess(t *testing.T) {
+	// Act
+	client, err := New(
+		WithURL("postgres://user@host:5432/database"),
+		WithFS(os.DirFS(filepath.Join("..", "testdata"))),
+	)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func Test_New_ReturnsError_InCaseOfMissingDatabase(t *testing.T) {
+	// Act
+	client, err := New(WithPath(filepath.Join("..", "testdata")))
+
+	// Assert
+	assert.EqualError(t, err, "please specify database connection or URL")
+	assert.Nil(t, client)
+}
+
+func Test_New_ReturnsError_InCaseOfMissingMigrations(t *testing.T) {
+	// Act
+	client, err := New(WithURL("postgres://user@host:5432/database"))
+
+	// Assert
+	assert.EqualError(t, err, "please specify migrations file system or path")
+	assert.Nil(t, client)
+}
+
+func Test_Down_ReturnsError_InCaseOfNonPositiveN(t *testing.T) {
+	// Arrange
+	client, err := New(WithURL("postgres://user@host:5432/database"), WithPath(filepath.Join("..", "testdata")))
+	assert.NoError(t, err)
+
+	// Act
+	result, err := client.Down(context.Background(), 0)
+
+	// Assert
+	assert.EqualError(t, err, "please specify a positive number of down migrations")
+	assert.Nil(t, result)
+}
+
+func Test_Up_ReturnsResult_InCaseOfSchemaFailure(t *testing.T) {
+	// Arrange
+	driverMock := &driver.Mock{}
+	driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", mock.AnythingOfType("driver.Table")).
+		Return(errors.New("failure")).Once()
+
+	client := &Client{
+		migrator: migrator.New(driverMock, &printer.Discard{}),
+		args: migrator.Args{
+			Path:                        filepath.Join("..", "testdata"),
+			URL:                         "connectionurl",
+			TimeoutDuration:             10 * time.Second,
+			DBConnectionTimeoutDuration: 10 * time.Second,
+			Schemas:                     []string{"tenant_1", "tenant_2"},
+		},
+	}
+
+	// Act
+	result, err := client.Up(context.Background())
+
+	// Assert
+	assert.EqualError(t, err, "migrating up failed: migrating 1 of 2 schema(s) failed")
+	if assert.NotNil(t, result) && assert.Len(t, result.Schemas, 2) {
+		assert.Equal(t, migrator.Failed, result.Schemas[0].State)
+		assert.Equal(t, migrator.Skipped, result.Schemas[1].State)
+	}
+
+	driverMock.AssertExpectations(t)
+}
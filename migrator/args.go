@@ -7,12 +7,33 @@ import (
 )
 
 type Args struct {
+	Concurrency                 int
+	ContinueOnError             bool
 	DBConnectionTimeoutDuration time.Duration
 	Direction                   direction.Direction
+	DryRun                      bool
+	LintDisable                 []string
+	LockTimeoutDuration         time.Duration
+	MaxAttempts                 int
+	NoChecksum                  bool
+	NoValidate                  bool
 	NoVerify                    bool
+	Output                      string
 	Path                        string
+	Quiet                       bool
+	RetryDelayDuration          time.Duration
+	RunTimeoutDuration          time.Duration
+	Schema                      string
+	SchemaPattern               string
+	Schemas                     []string
+	SearchPath                  bool
+	Since                       time.Time
 	Steps                       int
+	Table                       string
 	TimeoutDuration             time.Duration
+	Until                       time.Time
 	URL                         string
+	URLs                        []string
 	Verbose                     bool
+	Version                     int64
 }
@@ -0,0 +1,80 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/printer"
+	"github.com/wallester/migrate/version"
+)
+
+// History prints the up and down migrations recorded in the history table,
+// optionally only of the given version and started within Since and Until.
+// A missing history table is reported as an empty history.
+func (m *Migrator) History(args Args) ([]version.HistoryEntry, error) {
+	ctx := context.Background()
+	if err := m.open(ctx, args); err != nil {
+		return nil, err
+	}
+
+	defer m.close()
+
+	ctx, cancel := context.WithTimeout(ctx, args.TimeoutDuration)
+	defer cancel()
+
+	history, err := m.db.SelectHistory(ctx, driver.HistoryFilter{
+		Version: args.Version,
+		Since:   args.Since,
+		Until:   args.Until,
+	})
+	if errors.Is(err, driver.ErrNoMigrationsTable) {
+		history, err = nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Annotate(err, "selecting migration history failed")
+	}
+
+	for _, e := range history {
+		startedAt := e.StartedAt
+		if m.event(printer.Event{
+			Type:      printer.HistoryReported,
+			File:      e.File,
+			Version:   e.Version,
+			Direction: e.Direction,
+			StartedAt: &startedAt,
+			Duration:  e.Duration.Seconds(),
+			Checksum:  e.Checksum,
+			User:      e.DBUser,
+			Host:      e.ClientHostname,
+			Tool:      e.ToolVersion,
+		}) {
+			continue
+		}
+
+		host := e.ClientHostname
+		if host == "" {
+			host = "-"
+		}
+
+		m.output.Println(historyPrefix(e.Direction), e.StartedAt.Format(timeFormat), e.Version, e.Direction, e.File,
+			fmt.Sprintf("%.3fs", e.Duration.Seconds()), e.DBUser+"@"+host, e.ToolVersion)
+	}
+
+	m.event(printer.Event{Type: printer.Summary, Count: map[string]int{"history": len(history)}})
+
+	return history, nil
+}
+
+// private
+
+func historyPrefix(d string) string {
+	if d == direction.Up.ToString() {
+		return direction.Up.ToANSIColoredPrefix()
+	}
+
+	return direction.Down.ToANSIColoredPrefix()
+}
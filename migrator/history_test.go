@@ -0,0 +1,102 @@
+package migrator
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/version"
+)
+
+func (suite *MigratorTestSuite) Test_History_ReturnsHistory_InCaseOfSuccess() {
+	// Arrange
+	startedAt := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	history := []version.HistoryEntry{
+		{
+			ID:             1,
+			Version:        1494538273,
+			Direction:      "up",
+			File:           "1494538273_create_table_users.up.sql",
+			StartedAt:      startedAt,
+			FinishedAt:     startedAt.Add(1500 * time.Millisecond),
+			Duration:       1500 * time.Millisecond,
+			DBUser:         "deployer",
+			ClientHostname: "ci-runner",
+			ToolVersion:    "1.0.2",
+		},
+		{
+			ID:          2,
+			Version:     1494538273,
+			Direction:   "down",
+			File:        "1494538273_create_table_users.down.sql",
+			StartedAt:   startedAt.Add(time.Hour),
+			FinishedAt:  startedAt.Add(time.Hour),
+			DBUser:      "deployer",
+			ToolVersion: "1.0.2",
+		},
+	}
+
+	filter := driver.HistoryFilter{
+		Version: 1494538273,
+		Since:   startedAt.Truncate(24 * time.Hour),
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectHistory", mock.AnythingOfType("*context.timerCtx"), filter).Return(history, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+		Version:         1494538273,
+		Since:           filter.Since,
+	}
+
+	// Act
+	result, err := suite.instance.History(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(history, result)
+	suite.True(suite.output.Contains("2026-10-16 09:30:00 1494538273 up 1494538273_create_table_users.up.sql 1.500s deployer@ci-runner 1.0.2"))
+	suite.True(suite.output.Contains("1494538273 down 1494538273_create_table_users.down.sql 0.000s deployer@- 1.0.2"))
+}
+
+func (suite *MigratorTestSuite) Test_History_ReturnsError_InCaseOfDriverSelectHistoryError() {
+	// Arrange
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectHistory", mock.AnythingOfType("*context.timerCtx"), driver.HistoryFilter{}).Return(nil, suite.expectedErr).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	history, err := suite.instance.History(args)
+
+	// Assert
+	suite.EqualError(err, "selecting migration history failed: failure")
+	suite.Nil(history)
+}
+
+func (suite *MigratorTestSuite) Test_History_ReturnsEmptyHistory_InCaseOfMissingHistoryTable() {
+	// Arrange
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectHistory", mock.AnythingOfType("*context.timerCtx"), driver.HistoryFilter{}).Return(nil, driver.ErrNoMigrationsTable).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	history, err := suite.instance.History(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Empty(history)
+	suite.driverMock.AssertNotCalled(suite.T(), "CreateMigrationsTable", mock.Anything)
+}
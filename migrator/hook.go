@@ -0,0 +1,86 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/mgutz/ansi"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/printer"
+)
+
+// private
+
+func (m *Migrator) listHooks(args Args) (file.Hooks, error) {
+	if m.source != nil {
+		return m.source.ListHooks()
+	}
+
+	return file.NewDir(args.Path).ListHooks()
+}
+
+// withHooks sets the per-file hooks and runs the migrations between the before and after all hooks,
+// the hooks are neither set nor executed in a dry run
+func (m *Migrator) withHooks(ctx context.Context, args Args, run func() ([]Migration, error)) ([]Migration, error) {
+	if args.DryRun {
+		return run()
+	}
+
+	hooks, err := m.listHooks(args)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing hooks failed")
+	}
+
+	if hooks.Empty() {
+		return run()
+	}
+
+	m.db.SetHooks(hooks)
+
+	if err := m.executeHook(ctx, hooks.BeforeAll, args); err != nil {
+		return nil, err
+	}
+
+	migrations, err := run()
+	if err != nil {
+		return migrations, err
+	}
+
+	return migrations, m.executeHook(ctx, hooks.AfterAll, args)
+}
+
+// executeHook executes the before or after all hook if it exists
+func (m *Migrator) executeHook(ctx context.Context, hook *file.File, args Args) error {
+	if hook == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, migrationTimeout(*hook, args))
+	defer cancel()
+
+	if err := m.db.Execute(ctx, *hook); err != nil {
+		return errors.Annotatef(err, "executing %s hook failed", hook.Base)
+	}
+
+	if !m.event(printer.Event{Type: printer.HookExecuted, Schema: args.Schema, File: hook.Base}) && args.Verbose {
+		m.output.Println(fmt.Sprintf("%sExecuted hook:%s %s", ansi.Yellow, ansi.Reset, hook.Base))
+	}
+
+	return nil
+}
+
+// scriptHook returns the before or after all hook as a script section
+func scriptHook(hook *file.File) string {
+	if hook == nil {
+		return ""
+	}
+
+	body := strings.TrimRight(hook.SQL, " \t\r\n")
+	if !strings.HasSuffix(body, ";") {
+		body += "\n;"
+	}
+
+	return "\n-- " + hook.Base + "\n" + body + "\n"
+}
@@ -0,0 +1,133 @@
+package migrator
+
+import (
+	"context"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/version"
+)
+
+func (suite *MigratorTestSuite) Test_MigrateContext_ExecutesHooks_InCaseOfMigrationsToRun() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+		"_before_all.sql":       {Data: []byte("create extension if not exists pgcrypto;")},
+		"_before_each.sql":      {Data: []byte("set lock_timeout = '5s';")},
+		"_after_all.sql":        {Data: []byte("analyze;")},
+	}
+
+	hooks, err := file.ListHooksFS(fsys)
+	suite.Require().NoError(err)
+
+	upFiles, err := file.ListFilesFS(fsys, direction.Up)
+	suite.Require().NoError(err)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	var calls []string
+	record := func(name string) func(mock.Arguments) {
+		return func(mock.Arguments) {
+			calls = append(calls, name)
+		}
+	}
+
+	suite.expectRun(version.Migrations{})
+	suite.driverMock.On("SetHooks", hooks).Run(record("set hooks")).Once()
+	suite.driverMock.On("Execute", mock.AnythingOfType("*context.timerCtx"), *hooks.BeforeAll).Run(record("before all")).Return(nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), upFiles[0], direction.Up).Run(record("migrate")).Return(nil).Once()
+	suite.driverMock.On("Execute", mock.AnythingOfType("*context.timerCtx"), *hooks.AfterAll).Run(record("after all")).Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	_, err = instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal([]string{"set hooks", "before all", "migrate", "after all"}, calls)
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_SkipsAfterAllHook_InCaseOfMigrationError() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+		"_after_all.sql":        {Data: []byte("analyze;")},
+	}
+
+	hooks, err := file.ListHooksFS(fsys)
+	suite.Require().NoError(err)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.expectRun(version.Migrations{})
+	suite.driverMock.On("SetHooks", hooks).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), mock.AnythingOfType("file.File"), direction.Up).Return(suite.expectedErr).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	_, err = instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.EqualError(err, "migrating failed: applying migration failed: 1_create_table.up.sql: failure")
+}
+
+func (suite *MigratorTestSuite) Test_Redo_ExecutesHooks_InCaseOfMigrationsToRedo() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql":   {Data: []byte("create table t(id int);")},
+		"1_create_table.down.sql": {Data: []byte("drop table t;")},
+		"_before_all.sql":         {Data: []byte("create extension if not exists pgcrypto;")},
+		"_after_all.sql":          {Data: []byte("analyze;")},
+	}
+
+	hooks, err := file.ListHooksFS(fsys)
+	suite.Require().NoError(err)
+
+	upFiles, err := file.ListFilesFS(fsys, direction.Up)
+	suite.Require().NoError(err)
+
+	downFiles, err := file.ListFilesFS(fsys, direction.Down)
+	suite.Require().NoError(err)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	var calls []string
+	record := func(name string) func(mock.Arguments) {
+		return func(mock.Arguments) {
+			calls = append(calls, name)
+		}
+	}
+
+	suite.expectRun(version.Migrations{1: {Version: 1}})
+	suite.driverMock.On("SetHooks", hooks).Run(record("set hooks")).Once()
+	suite.driverMock.On("Execute", mock.AnythingOfType("*context.timerCtx"), *hooks.BeforeAll).Run(record("before all")).Return(nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), downFiles[0], direction.Down).Run(record("migrate down")).Return(nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), upFiles[0], direction.Up).Run(record("migrate up")).Return(nil).Once()
+	suite.driverMock.On("Execute", mock.AnythingOfType("*context.timerCtx"), *hooks.AfterAll).Run(record("after all")).Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		Steps:           1,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	err = instance.RedoContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal([]string{"set hooks", "before all", "migrate down", "migrate up", "after all"}, calls)
+}
@@ -0,0 +1,56 @@
+package migrator
+
+import (
+	"path/filepath"
+
+	"github.com/juju/errors"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/lint"
+	"github.com/wallester/migrate/printer"
+)
+
+// Lint checks the migration files for risky patterns and prints the findings
+func (m *Migrator) Lint(args Args) ([]lint.Finding, error) {
+	upFiles, err := m.listFiles(args, direction.Up)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing up migration files failed")
+	}
+
+	downFiles, err := m.listFiles(args, direction.Down)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing down migration files failed")
+	}
+
+	findings, err := lint.Lint(upFiles, downFiles, args.LintDisable)
+	if err != nil {
+		return nil, errors.Annotate(err, "linting migration files failed")
+	}
+
+	for _, f := range findings {
+		m.printFinding(f, args)
+	}
+
+	m.event(printer.Event{Type: printer.Summary, Count: map[string]int{"lint": len(findings)}})
+
+	return findings, nil
+}
+
+// private
+
+// printFinding prints the finding with the path of its file
+func (m *Migrator) printFinding(f lint.Finding, args Args) {
+	if m.source == nil {
+		f.File = filepath.Join(args.Path, f.File)
+	}
+
+	if p, ok := m.output.(printer.IAnnotationPrinter); ok {
+		p.Annotate(printer.Annotation{File: f.File, Line: f.Line, Title: "migrate lint: " + f.Rule, Message: f.Message})
+		return
+	}
+
+	if m.event(printer.Event{Type: printer.Linted, File: f.File, Line: f.Line, Rule: f.Rule, Message: f.Message}) {
+		return
+	}
+
+	m.output.Println(f.String())
+}
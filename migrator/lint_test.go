@@ -0,0 +1,58 @@
+package migrator
+
+import (
+	"testing/fstest"
+
+	"github.com/wallester/migrate/lint"
+)
+
+func (suite *MigratorTestSuite) Test_Lint_ReturnsFindings_InCaseOfRiskyMigrations() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_drop_table.up.sql":   {Data: []byte("drop table logs;")},
+		"1_drop_table.down.sql": {Data: []byte("create table logs(id int);")},
+		"2_add_index.up.sql":    {Data: []byte("create index users_name_idx on users(name);")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	// Act
+	findings, err := instance.Lint(Args{})
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(findings, 3)
+	suite.Equal("1_drop_table.up.sql:1: drop-table: dropping a table in an up migration loses data\n"+
+		"2_add_index.up.sql: missing-down: up migration has no down migration\n"+
+		"2_add_index.up.sql:1: create-index-not-concurrently: creating an index without CONCURRENTLY blocks writes to users",
+		suite.output.String())
+}
+
+func (suite *MigratorTestSuite) Test_Lint_ReturnsNoFindings_InCaseOfDisabledRules() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_drop_table.up.sql": {Data: []byte("drop table logs;")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	// Act
+	findings, err := instance.Lint(Args{LintDisable: []string{lint.DropTable, lint.MissingDown}})
+
+	// Assert
+	suite.NoError(err)
+	suite.Empty(findings)
+	suite.Empty(suite.output.String())
+}
+
+func (suite *MigratorTestSuite) Test_Lint_ReturnsError_InCaseOfUnknownRule() {
+	// Arrange
+	instance := NewWithFS(suite.driverMock, suite.output, fstest.MapFS{})
+
+	// Act
+	findings, err := instance.Lint(Args{LintDisable: []string{"drop-everything"}})
+
+	// Assert
+	suite.EqualError(err, `linting migration files failed: unknown lint rule "drop-everything"`)
+	suite.Nil(findings)
+}
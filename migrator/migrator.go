@@ -3,6 +3,9 @@ package migrator
 import (
 	"context"
 	"fmt"
+	"io/fs"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -11,19 +14,41 @@ import (
 	"github.com/wallester/migrate/direction"
 	"github.com/wallester/migrate/driver"
 	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/lint"
 	"github.com/wallester/migrate/printer"
 	"github.com/wallester/migrate/version"
 )
 
+// ErrPendingMigrations is returned by dry runs when there are migrations to apply
+var ErrPendingMigrations = errors.New("there are migrations to apply")
+
+// ErrInterrupted is returned when the context is canceled while migrating
+var ErrInterrupted = errors.New("migrating was interrupted")
+
 // IMigrator represents possible migration actions
 type IMigrator interface {
 	Migrate(args Args) error
+	MigrateContext(ctx context.Context, args Args) (*Result, error)
 	Create(name, path string, verbose bool) (*file.Pair, error)
+	Status(args Args) (Statuses, error)
+	Repair(args Args) ([]file.File, error)
+	Force(args Args) error
+	Goto(args Args) error
+	GotoContext(ctx context.Context, args Args) error
+	Redo(args Args) error
+	RedoContext(ctx context.Context, args Args) error
+	Script(args Args) ([]file.File, error)
+	ScriptContext(ctx context.Context, args Args) ([]file.File, error)
+	History(args Args) ([]version.HistoryEntry, error)
+	Lint(args Args) ([]lint.Finding, error)
+	Validate(args Args) ([]file.Problem, error)
 }
 
 type Migrator struct {
-	db     driver.IDriver
-	output printer.IPrinter
+	db        driver.IDriver
+	newDriver func() driver.IDriver
+	output    printer.IPrinter
+	source    file.ISource
 }
 
 var _ IMigrator = (*Migrator)(nil)
@@ -36,42 +61,64 @@ func New(db driver.IDriver, output printer.IPrinter) *Migrator {
 	}
 }
 
+// NewWithFS returns new instance that reads migration files from the root of
+// the given file system instead of Args.Path
+func NewWithFS(db driver.IDriver, output printer.IPrinter, fsys fs.FS) *Migrator {
+	return &Migrator{
+		db:     db,
+		output: output,
+		source: file.NewFS(fsys),
+	}
+}
+
+// SetOutput replaces the printer
+func (m *Migrator) SetOutput(output printer.IPrinter) {
+	m.output = output
+}
+
+// SetDriverFactory sets the function that creates a driver for every database
+// when migrating multiple URLs
+func (m *Migrator) SetDriverFactory(newDriver func() driver.IDriver) {
+	m.newDriver = newDriver
+}
+
 // Migrate migrates up or down
 func (m *Migrator) Migrate(args Args) error {
-	started := time.Now()
+	_, err := m.MigrateContext(context.Background(), args)
+	return err
+}
 
-	files, err := file.ListFiles(args.Path, args.Direction)
-	if err != nil {
-		return errors.Annotate(err, "listing migration files failed")
+// MigrateContext migrates up or down within the given context and returns the migrated files.
+// The migration files are validated first unless NoValidate is set.
+// With URLs every database, and with Schemas or SchemaPattern every schema is migrated
+// separately and the result is returned also when some of them failed.
+func (m *Migrator) MigrateContext(ctx context.Context, args Args) (*Result, error) {
+	if !args.NoValidate {
+		if err := m.validateFiles(args); err != nil {
+			return nil, err
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), args.DBConnectionTimeoutDuration)
-	defer cancel()
-	if err := m.db.Open(ctx, args.URL); err != nil {
-		return errors.Annotate(err, "opening database connection failed")
+	if len(args.URLs) > 0 {
+		return m.migrateTargets(ctx, args)
 	}
 
-	defer func() {
-		if err := m.db.Close(); err != nil {
-			m.output.Println(errors.Annotate(err, "closing database connection failed").Error())
-		}
-	}()
-
-	migratedFiles, err := m.applyMigrations(files, args)
-	if err != nil {
-		return errors.Annotate(err, "migrating failed")
+	if len(args.Schemas) > 0 || args.SchemaPattern != "" {
+		return m.migrateSchemas(ctx, args)
 	}
 
-	if !args.Verbose {
-		for _, f := range migratedFiles {
-			m.output.Println(args.Direction.ToANSIColoredPrefix(), f.Base)
-		}
-	}
+	started := time.Now()
 
-	spent := time.Since(started).Seconds()
-	m.output.Println(fmt.Sprintf("%sTotal migration time:%s %.4f seconds", ansi.Green, ansi.Reset, spent))
+	migrations, err := m.migrate(ctx, args)
+	m.printSummary(migrations, started, args, err)
+	if err != nil {
+		return nil, err
+	}
 
-	return nil
+	return &Result{
+		Migrations: migrations,
+		Duration:   time.Since(started),
+	}, nil
 }
 
 func (m *Migrator) Create(name, path string, verbose bool) (*file.Pair, error) {
@@ -94,7 +141,10 @@ func (m *Migrator) Create(name, path string, verbose bool) (*file.Pair, error) {
 		return nil, errors.Annotate(err, "writing down migration file failed")
 	}
 
-	if verbose {
+	if m.structured() {
+		m.event(printer.Event{Type: printer.Created, File: up.Base, Version: v, Direction: direction.Up.ToString()})
+		m.event(printer.Event{Type: printer.Created, File: down.Base, Version: v, Direction: direction.Down.ToString()})
+	} else if verbose {
 		m.output.Println("Version", v, "migration files created in", path)
 		m.output.Println(up.Base)
 		m.output.Println(down.Base)
@@ -106,11 +156,154 @@ func (m *Migrator) Create(name, path string, verbose bool) (*file.Pair, error) {
 	}, nil
 }
 
-// private
+// Status returns applied, pending and orphaned migrations
+func (m *Migrator) Status(args Args) (Statuses, error) {
+	return m.StatusContext(context.Background(), args)
+}
 
-const timeFormat = "2006-01-02 15:04:05.999999999"
+// StatusContext returns applied, pending and orphaned migrations within the given context
+func (m *Migrator) StatusContext(ctx context.Context, args Args) (Statuses, error) {
+	files, err := m.listFiles(args, direction.Up)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing migration files failed")
+	}
+
+	repeatables, err := m.listRepeatableFiles(args)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing repeatable migration files failed")
+	}
+
+	if err := m.open(ctx, args); err != nil {
+		return nil, err
+	}
+
+	defer m.close()
+
+	ctx, cancel := context.WithTimeout(ctx, args.TimeoutDuration)
+	defer cancel()
+
+	migrations, _, err := m.readMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(Statuses, 0, len(files)+len(repeatables)+len(migrations))
+	for _, f := range append(files, repeatables...) {
+		s := Status{
+			Version:    f.Version,
+			Base:       f.Base,
+			Repeatable: f.Repeatable,
+			State:      Pending,
+		}
+
+		if migration, ok := migrations[f.Version]; ok {
+			s.State = Applied
+			s.AppliedAt = migration.AppliedAt
+			if migration.Dirty {
+				s.State = Dirty
+			} else if f.Repeatable && migration.Checksum != f.Checksum() {
+				s.State = Pending
+			}
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	for v, migration := range migrations {
+		if file.FindByVersion(v, files) != nil || file.FindByVersion(v, repeatables) != nil {
+			continue
+		}
+
+		s := Status{
+			Version:    v,
+			Base:       migration.Name,
+			Repeatable: migration.Name != "",
+			State:      Orphaned,
+			AppliedAt:  migration.AppliedAt,
+		}
+
+		if migration.Dirty {
+			s.State = Dirty
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Repeatable != statuses[j].Repeatable {
+			return !statuses[i].Repeatable
+		}
+
+		if statuses[i].Repeatable {
+			return statuses[i].Base < statuses[j].Base
+		}
+
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	for _, s := range statuses {
+		e := printer.Event{Type: printer.StatusReported, File: s.Base, Version: s.Version, State: string(s.State), AppliedAt: s.AppliedAt}
+		if s.Repeatable {
+			e.Version = 0
+		}
+
+		if m.event(e) {
+			continue
+		}
+
+		appliedAt := "-"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(timeFormat)
+		}
+
+		base := s.Base
+		if base == "" {
+			base = "-"
+		}
+
+		v := fmt.Sprint(s.Version)
+		if s.Repeatable {
+			v = "R"
+		}
+
+		m.output.Println(v, s.State.ToANSIColoredString(), appliedAt, base)
+	}
+
+	count := map[string]int{
+		string(Applied):  statuses.Count(Applied),
+		string(Pending):  statuses.Count(Pending),
+		string(Orphaned): statuses.Count(Orphaned),
+		string(Dirty):    statuses.Count(Dirty),
+	}
+
+	if !m.event(printer.Event{Type: printer.Summary, Count: count}) && args.Verbose {
+		m.output.Println(
+			fmt.Sprintf(
+				"%sApplied:%s %d, %sPending:%s %d, %sOrphaned:%s %d, %sDirty:%s %d",
+				ansi.Green, ansi.Reset, statuses.Count(Applied),
+				ansi.Yellow, ansi.Reset, statuses.Count(Pending),
+				ansi.Red, ansi.Reset, statuses.Count(Orphaned),
+				ansi.Red, ansi.Reset, statuses.Count(Dirty),
+			),
+		)
+	}
+
+	return statuses, nil
+}
+
+// Repair updates checksums of already migrated migrations whose files have changed
+func (m *Migrator) Repair(args Args) ([]file.File, error) {
+	files, err := m.listFiles(args, direction.Up)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing migration files failed")
+	}
+
+	if err := m.open(context.Background(), args); err != nil {
+		return nil, err
+	}
+
+	defer m.close()
 
-func (m *Migrator) applyMigrations(files []file.File, args Args) ([]file.File, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), args.TimeoutDuration)
 	defer cancel()
 
@@ -118,16 +311,503 @@ func (m *Migrator) applyMigrations(files []file.File, args Args) ([]file.File, e
 		return nil, errors.Annotate(err, "creating migrations table failed")
 	}
 
-	alreadyMigrated, err := m.db.SelectAllMigrations(ctx)
+	migrations, err := m.db.SelectMigrations(ctx)
 	if err != nil {
 		return nil, errors.Annotate(err, "selecting existing migrations failed")
 	}
 
+	repaired := make([]file.File, 0, len(files))
+	for _, f := range files {
+		migration, ok := migrations[f.Version]
+		if !ok || migration.Checksum == f.Checksum() {
+			continue
+		}
+
+		if err := m.db.UpdateChecksum(ctx, f); err != nil {
+			return nil, errors.Annotatef(err, "repairing migration failed: %s", f.Base)
+		}
+
+		if !m.event(printer.Event{Type: printer.Repaired, File: f.Base, Version: f.Version}) {
+			m.output.Println(direction.Up.ToANSIColoredPrefix(), f.Base)
+		}
+
+		repaired = append(repaired, f)
+	}
+
+	if m.event(printer.Event{Type: printer.Summary, Count: map[string]int{"repaired": len(repaired)}}) {
+		return repaired, nil
+	}
+
+	if len(repaired) == 0 && args.Verbose {
+		m.output.Println("nothing to repair")
+	}
+
+	return repaired, nil
+}
+
+// Goto migrates down and then up to land exactly on the given version
+func (m *Migrator) Goto(args Args) error {
+	return m.GotoContext(context.Background(), args)
+}
+
+// GotoContext migrates down and then up to land exactly on the given version within the given context
+func (m *Migrator) GotoContext(ctx context.Context, args Args) error {
+	started := time.Now()
+
+	migrations, err := m.gotoVersion(ctx, args)
+	m.printSummary(migrations, started, args, err)
+
+	return err
+}
+
+// Redo migrates down and up again the latest <n> migrations
+func (m *Migrator) Redo(args Args) error {
+	return m.RedoContext(context.Background(), args)
+}
+
+// RedoContext migrates down and up again the latest <n> migrations within the given context
+func (m *Migrator) RedoContext(ctx context.Context, args Args) error {
+	started := time.Now()
+
+	migrations, err := m.redo(ctx, args)
+	m.printSummary(migrations, started, args, err)
+
+	return err
+}
+
+// Script writes a SQL script that applies <n> or all up migrations when run with psql
+func (m *Migrator) Script(args Args) ([]file.File, error) {
+	return m.ScriptContext(context.Background(), args)
+}
+
+// ScriptContext writes a SQL script that applies <n> or all up migrations when run with psql within the given context.
+// The database is only read, a missing migrations table is created by the script.
+func (m *Migrator) ScriptContext(ctx context.Context, args Args) ([]file.File, error) {
+	files, err := m.listFiles(args, direction.Up)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing migration files failed")
+	}
+
+	if err := m.open(ctx, args); err != nil {
+		return nil, err
+	}
+
+	defer m.close()
+
+	ctx, cancel := runContext(ctx, args)
+	defer cancel()
+
+	selectCtx, cancelSelect := context.WithTimeout(ctx, args.TimeoutDuration)
+	defer cancelSelect()
+
+	alreadyMigrated, exists, err := m.readMigrations(selectCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, dirty := alreadyMigrated.Dirty(); dirty {
+		return nil, newDirtyError(v)
+	}
+
+	args.Direction = direction.Up
+	needsMigration, err := m.chooseMigrations(files, alreadyMigrated, args)
+	if err != nil {
+		return nil, errors.Annotate(err, "choosing migrations failed")
+	}
+
+	if repeatsAll(args) {
+		repeatables, err := m.chooseRepeatableMigrations(alreadyMigrated, args)
+		if err != nil {
+			return nil, err
+		}
+
+		needsMigration = append(needsMigration, repeatables...)
+	}
+
+	hooks, err := m.listHooks(args)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing hooks failed")
+	}
+
+	if !hooks.Empty() {
+		m.db.SetHooks(hooks)
+	}
+
+	var b strings.Builder
+	b.WriteString("\\set ON_ERROR_STOP on\n")
+	if len(needsMigration) > 0 && !exists {
+		b.WriteString("\n" + m.db.MigrationsTableScript())
+	}
+
+	if len(needsMigration) > 0 {
+		b.WriteString(scriptHook(hooks.BeforeAll))
+	}
+
+	for _, f := range needsMigration {
+		if f.Func != nil {
+			return nil, fmt.Errorf("cannot write Go migration %s to a script", f.Base)
+		}
+
+		b.WriteString("\n" + m.db.Script(f, direction.Up))
+	}
+
+	if len(needsMigration) > 0 {
+		b.WriteString(scriptHook(hooks.AfterAll))
+	}
+
+	if args.Output == "" {
+		if !m.event(printer.Event{Type: printer.Script, SQL: b.String()}) {
+			m.output.Println(b.String())
+		}
+
+		return needsMigration, nil
+	}
+
+	if err := os.WriteFile(args.Output, []byte(b.String()), 0o600); err != nil {
+		return nil, errors.Annotate(err, "writing script file failed")
+	}
+
+	if args.Verbose {
+		m.output.Println(len(needsMigration), "migration(s) written to", args.Output)
+	}
+
+	return needsMigration, nil
+}
+
+// Force marks a version as applied (up) or not applied (down) without executing it
+func (m *Migrator) Force(args Args) error {
+	files, err := m.listFiles(args, direction.Up)
+	if err != nil {
+		return errors.Annotate(err, "listing migration files failed")
+	}
+
+	f := file.FindByVersion(args.Version, files)
+	if f == nil {
+		if args.Direction == direction.Up {
+			return fmt.Errorf("migration file for version %d not found", args.Version)
+		}
+
+		f = &file.File{Version: args.Version}
+	}
+
+	if err := m.open(context.Background(), args); err != nil {
+		return err
+	}
+
+	defer m.close()
+
+	if err := m.lock(context.Background(), args); err != nil {
+		return errors.Annotate(err, "locking failed")
+	}
+
+	defer m.unlock(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), args.TimeoutDuration)
+	defer cancel()
+
+	if err := m.db.CreateMigrationsTable(ctx); err != nil {
+		return errors.Annotate(err, "creating migrations table failed")
+	}
+
+	if err := m.db.Force(ctx, *f, args.Direction); err != nil {
+		return errors.Annotate(err, "forcing version failed")
+	}
+
+	if !m.event(printer.Event{Type: printer.Forced, File: f.Base, Version: f.Version, Direction: args.Direction.ToString()}) {
+		m.output.Println(args.Direction.ToANSIColoredPrefix(), "Forced version", args.Version, args.Direction.ToString())
+	}
+
+	return nil
+}
+
+// private
+
+func (m *Migrator) migrate(ctx context.Context, args Args) ([]Migration, error) {
+	files, err := m.listFiles(args, args.Direction)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing migration files failed")
+	}
+
+	if err := m.open(ctx, args); err != nil {
+		return nil, err
+	}
+
+	defer m.close()
+
+	if err := m.lock(ctx, args); err != nil {
+		return nil, errors.Annotate(err, "locking failed")
+	}
+
+	defer m.unlock(args)
+
+	migrations, err := m.applyMigrations(ctx, files, args)
+	if err != nil {
+		return migrations, errors.Annotate(err, "migrating failed")
+	}
+
+	m.printMigrated(migrations, args)
+
+	if args.DryRun && len(migrations) > 0 {
+		return migrations, ErrPendingMigrations
+	}
+
+	return migrations, nil
+}
+
+func (m *Migrator) gotoVersion(ctx context.Context, args Args) ([]Migration, error) {
+	upFiles, err := m.listFiles(args, direction.Up)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing migration files failed")
+	}
+
+	downFiles, err := m.listFiles(args, direction.Down)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing migration files failed")
+	}
+
+	if file.FindByVersion(args.Version, upFiles) == nil {
+		return nil, fmt.Errorf("migration file for version %d not found", args.Version)
+	}
+
+	if err := m.open(ctx, args); err != nil {
+		return nil, err
+	}
+
+	defer m.close()
+
+	if err := m.lock(ctx, args); err != nil {
+		return nil, errors.Annotate(err, "locking failed")
+	}
+
+	defer m.unlock(args)
+
+	ctx, cancel := runContext(ctx, args)
+	defer cancel()
+
+	alreadyMigrated, err := m.selectMigrations(ctx, args)
+	if err != nil {
+		return nil, errors.Annotate(err, "migrating failed")
+	}
+
+	downs, ups, err := m.chooseTargetMigrations(upFiles, downFiles, alreadyMigrated, args)
+	if err != nil {
+		return nil, errors.Annotate(err, "migrating failed: choosing migrations failed")
+	}
+
+	if len(downs)+len(ups) == 0 {
+		if args.Verbose {
+			m.output.Println("nothing to migrate")
+		}
+
+		return nil, nil
+	}
+
+	migrations, err := m.withHooks(ctx, args, func() ([]Migration, error) {
+		downMigrations, err := m.runMigrations(ctx, downs, direction.Down, args)
+		if err != nil {
+			return downMigrations, errors.Annotate(err, "migrating down failed")
+		}
+
+		m.printMigrated(downMigrations, args)
+
+		upMigrations, err := m.runMigrations(ctx, ups, direction.Up, args)
+		migrations := append(downMigrations, upMigrations...)
+		if err != nil {
+			return migrations, errors.Annotate(err, "migrating up failed")
+		}
+
+		m.printMigrated(upMigrations, args)
+
+		return migrations, nil
+	})
+	if err != nil {
+		return migrations, err
+	}
+
+	if args.DryRun && len(migrations) > 0 {
+		return migrations, ErrPendingMigrations
+	}
+
+	return migrations, nil
+}
+
+func (m *Migrator) redo(ctx context.Context, args Args) ([]Migration, error) {
+	upFiles, err := m.listFiles(args, direction.Up)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing migration files failed")
+	}
+
+	downFiles, err := m.listFiles(args, direction.Down)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing migration files failed")
+	}
+
+	if err := m.open(ctx, args); err != nil {
+		return nil, err
+	}
+
+	defer m.close()
+
+	if err := m.lock(ctx, args); err != nil {
+		return nil, errors.Annotate(err, "locking failed")
+	}
+
+	defer m.unlock(args)
+
+	ctx, cancel := runContext(ctx, args)
+	defer cancel()
+
+	alreadyMigrated, err := m.selectMigrations(ctx, args)
+	if err != nil {
+		return nil, errors.Annotate(err, "migrating failed")
+	}
+
+	args.Direction = direction.Down
+	downs, err := m.chooseMigrations(downFiles, alreadyMigrated, args)
+	if err != nil {
+		return nil, errors.Annotate(err, "migrating failed: choosing migrations failed")
+	}
+
+	ups := make([]file.File, 0, len(downs))
+	for i := len(downs) - 1; i >= 0; i-- {
+		f := file.FindByVersion(downs[i].Version, upFiles)
+		if f == nil {
+			return nil, fmt.Errorf("up migration file for version %d not found", downs[i].Version)
+		}
+
+		ups = append(ups, *f)
+	}
+
+	if len(downs) == 0 {
+		if args.Verbose {
+			m.output.Println("nothing to migrate")
+		}
+
+		return nil, nil
+	}
+
+	migrations, err := m.withHooks(ctx, args, func() ([]Migration, error) {
+		downMigrations, err := m.runMigrations(ctx, downs, direction.Down, args)
+		if err != nil {
+			return downMigrations, errors.Annotate(err, "migrating down failed, no up migrations were applied")
+		}
+
+		m.printMigrated(downMigrations, args)
+
+		upMigrations, err := m.runMigrations(ctx, ups, direction.Up, args)
+		migrations := append(downMigrations, upMigrations...)
+		if err != nil {
+			return migrations, errors.Annotate(err, "migrating up failed")
+		}
+
+		m.printMigrated(upMigrations, args)
+
+		return migrations, nil
+	})
+	if err != nil {
+		return migrations, err
+	}
+
+	if args.DryRun && len(migrations) > 0 {
+		return migrations, ErrPendingMigrations
+	}
+
+	return migrations, nil
+}
+
+const timeFormat = "2006-01-02 15:04:05.999999999"
+
+// listFiles lists the migration files of the direction together with the registered Go migrations
+func (m *Migrator) listFiles(args Args, d direction.Direction) ([]file.File, error) {
+	source := m.source
+	if source == nil {
+		source = file.NewDir(args.Path)
+	}
+
+	files, err := source.ListFiles(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return withGoMigrations(files, d)
+}
+
+func newDirtyError(v int64) error {
+	return fmt.Errorf("version %d is dirty, fix the database manually and run force", v)
+}
+
+func (m *Migrator) open(ctx context.Context, args Args) error {
+	ctx, cancel := context.WithTimeout(ctx, args.DBConnectionTimeoutDuration)
+	defer cancel()
+
+	if err := m.db.Open(ctx, args.URL, driver.Table{Schema: args.Schema, Name: args.Table, SearchPath: args.SearchPath}); err != nil {
+		return errors.Annotate(err, "opening database connection failed")
+	}
+
+	return nil
+}
+
+func (m *Migrator) close() {
+	if err := m.db.Close(); err != nil {
+		m.output.Println(errors.Annotate(err, "closing database connection failed").Error())
+	}
+}
+
+func (m *Migrator) lock(ctx context.Context, args Args) error {
+	if args.DryRun {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, args.LockTimeoutDuration)
+	defer cancel()
+
+	if err := m.db.Lock(ctx); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return errors.Annotatef(err, "could not acquire migration lock within %s, another migration may be running", args.LockTimeoutDuration)
+		}
+
+		return errors.Annotate(err, "acquiring migration lock failed")
+	}
+
+	return nil
+}
+
+func (m *Migrator) unlock(args Args) {
+	if args.DryRun {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), args.DBConnectionTimeoutDuration)
+	defer cancel()
+
+	if err := m.db.Unlock(ctx); err != nil {
+		m.output.Println(errors.Annotate(err, "releasing migration lock failed").Error())
+	}
+}
+
+func (m *Migrator) applyMigrations(ctx context.Context, files []file.File, args Args) ([]Migration, error) {
+	ctx, cancel := runContext(ctx, args)
+	defer cancel()
+
+	alreadyMigrated, err := m.selectMigrations(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
 	needsMigration, err := m.chooseMigrations(files, alreadyMigrated, args)
 	if err != nil {
 		return nil, errors.Annotate(err, "choosing migrations failed")
 	}
 
+	if repeatsAll(args) {
+		repeatables, err := m.chooseRepeatableMigrations(alreadyMigrated, args)
+		if err != nil {
+			return nil, err
+		}
+
+		needsMigration = append(needsMigration, repeatables...)
+	}
+
 	if len(needsMigration) == 0 {
 		if args.Verbose {
 			m.output.Println("nothing to migrate")
@@ -136,49 +816,283 @@ func (m *Migrator) applyMigrations(files []file.File, args Args) ([]file.File, e
 		return nil, nil
 	}
 
-	for _, f := range needsMigration {
+	return m.withHooks(ctx, args, func() ([]Migration, error) {
+		return m.runMigrations(ctx, needsMigration, args.Direction, args)
+	})
+}
+
+// selectMigrations creates the migrations table if needed and selects existing migrations within the timeout,
+// failing if any of them is dirty. A dry run does not create the table and treats a missing one as empty.
+func (m *Migrator) selectMigrations(ctx context.Context, args Args) (version.Migrations, error) {
+	ctx, cancel := context.WithTimeout(ctx, args.TimeoutDuration)
+	defer cancel()
+
+	if !args.DryRun {
+		if err := m.db.CreateMigrationsTable(ctx); err != nil {
+			return nil, errors.Annotate(err, "creating migrations table failed")
+		}
+	}
+
+	alreadyMigrated, _, err := m.readMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, dirty := alreadyMigrated.Dirty(); dirty {
+		return nil, newDirtyError(v)
+	}
+
+	return alreadyMigrated, nil
+}
+
+// readMigrations selects existing migrations without creating the migrations table,
+// a missing table is reported as not existing and without migrations
+func (m *Migrator) readMigrations(ctx context.Context) (version.Migrations, bool, error) {
+	migrations, err := m.db.SelectMigrations(ctx)
+	if errors.Is(err, driver.ErrNoMigrationsTable) {
+		return version.Migrations{}, false, nil
+	}
+
+	if err != nil {
+		return nil, false, errors.Annotate(err, "selecting existing migrations failed")
+	}
+
+	return migrations, true, nil
+}
+
+// runMigrations runs the files in order and returns the migrations that have been run,
+// including the ones run before a failure
+func (m *Migrator) runMigrations(ctx context.Context, files []file.File, d direction.Direction, args Args) ([]Migration, error) {
+	migrations := make([]Migration, 0, len(files))
+	for _, f := range files {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return migrations, errors.Annotatef(ErrInterrupted, "not started: %s", f.Base)
+		}
+
+		if args.DryRun {
+			m.printPlan(f, d, args)
+			migrations = append(migrations, Migration{File: f, Direction: d})
+			continue
+		}
+
 		migrationStartedAt := time.Now()
-		if args.Verbose {
+		e := printer.Event{Type: printer.Started, Schema: args.Schema, File: f.Base, Version: f.Version, Direction: d.ToString()}
+		if !m.event(e) && args.Verbose {
 			m.output.Println(
 				fmt.Sprintf(
 					"%s Started %s at %s",
-					args.Direction.ToANSIColoredPrefix(),
+					d.ToANSIColoredPrefix(),
 					f.Base,
 					migrationStartedAt.Format(timeFormat),
 				),
 			)
 		}
-		
-		if err := m.db.Migrate(ctx, f, args.Direction); err != nil {
-			return nil, errors.Annotatef(err, "applying migration failed: %s", f.Base)
+
+		if err := m.migrateWithRetry(ctx, f, d, args); err != nil {
+			e.Type, e.Duration, e.Error = printer.Failed, time.Since(migrationStartedAt).Seconds(), err.Error()
+			structured := m.event(e)
+
+			if errors.Is(ctx.Err(), context.Canceled) {
+				if !structured {
+					m.output.Println(d.ToANSIColoredPrefix(), "Interrupted", f.Base)
+				}
+
+				return migrations, errors.Annotatef(ErrInterrupted, "applying migration interrupted: %s", f.Base)
+			}
+
+			return migrations, errors.Annotatef(err, "applying migration failed: %s", f.Base)
 		}
 
-		if args.Verbose {
+		spent := time.Since(migrationStartedAt)
+		migrations = append(migrations, Migration{File: f, Direction: d, Duration: spent})
+
+		e.Type, e.Duration = printer.Finished, spent.Seconds()
+		if !m.event(e) && args.Verbose {
 			migrationFinishedAt := time.Now()
 			m.output.Println(
 				fmt.Sprintf(
 					"%s Finished %s at %s (%0.4f seconds)",
-					args.Direction.ToANSIColoredPrefix(),
+					d.ToANSIColoredPrefix(),
 					f.Base,
 					migrationFinishedAt.Format(timeFormat),
-					time.Since(migrationStartedAt).Seconds(),
+					spent.Seconds(),
 				),
 			)
 		}
 	}
 
-	return needsMigration, nil
+	return migrations, nil
 }
 
-func (m *Migrator) chooseMigrations(files []file.File, alreadyMigrated version.Versions, args Args) ([]file.File, error) {
-	maxMigratedVersion := alreadyMigrated.Max()
+// runContext returns the context of a migration run, limited by the run timeout if any
+func runContext(ctx context.Context, args Args) (context.Context, context.CancelFunc) {
+	if args.RunTimeoutDuration > 0 {
+		return context.WithTimeout(ctx, args.RunTimeoutDuration)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// migrationTimeout returns the timeout directive of the file or the default timeout
+func migrationTimeout(f file.File, args Args) time.Duration {
+	if f.Timeout > 0 {
+		return f.Timeout
+	}
+
+	return args.TimeoutDuration
+}
+
+func (m *Migrator) chooseTargetMigrations(upFiles, downFiles []file.File, alreadyMigrated version.Migrations, args Args) ([]file.File, []file.File, error) {
+	var exists struct{}
+	remaining := make(version.Versions, len(alreadyMigrated))
+	for v := range alreadyMigrated {
+		if v <= args.Version {
+			remaining[v] = exists
+			continue
+		}
+
+		if file.FindByVersion(v, downFiles) == nil {
+			return nil, nil, fmt.Errorf("cannot migrate down version %d, because its down migration file does not exist", v)
+		}
+	}
+
+	downs := make([]file.File, 0, len(alreadyMigrated)-len(remaining))
+	for _, f := range downFiles {
+		if _, isMigrated := alreadyMigrated[f.Version]; isMigrated && f.Version > args.Version {
+			downs = append(downs, f)
+		}
+	}
+
+	maxRemainingVersion := remaining.Max()
+	ups := make([]file.File, 0, len(upFiles))
+	for _, f := range upFiles {
+		if f.Version > args.Version {
+			break
+		}
+
+		if migration, isMigrated := alreadyMigrated[f.Version]; isMigrated {
+			if err := verifyChecksum(f, migration, args); err != nil {
+				return nil, nil, err
+			}
+
+			continue
+		}
+
+		if maxRemainingVersion > f.Version && !args.NoVerify {
+			return nil, nil, fmt.Errorf("cannot migrate up %s, because it's older than already migrated version %d", f.Base, maxRemainingVersion)
+		}
+
+		ups = append(ups, f)
+	}
+
+	if args.Verbose && len(downs)+len(ups) > 0 {
+		m.output.Println(fmt.Sprintf("%sFiles to be migrated:%s %d down, %d up", ansi.Yellow, ansi.Reset, len(downs), len(ups)))
+	}
+
+	return downs, ups, nil
+}
+
+func verifyChecksum(f file.File, migration version.Migration, args Args) error {
+	if args.NoChecksum || migration.Checksum == "" || migration.Checksum == f.Checksum() {
+		return nil
+	}
+
+	return fmt.Errorf("checksum of %s does not match already migrated version %d, run repair if the change was intentional", f.Base, f.Version)
+}
+
+func (m *Migrator) printPlan(f file.File, d direction.Direction, args Args) {
+	e := printer.Event{Type: printer.Planned, Schema: args.Schema, File: f.Base, Version: f.Version, Direction: d.ToString()}
+	if !args.Quiet {
+		e.SQL = f.SQL
+	}
+
+	if m.event(e) {
+		return
+	}
+
+	m.output.Println(d.ToANSIColoredPrefix(), f.Base)
+	if !args.Quiet {
+		m.output.Println(f.SQL)
+	}
+}
+
+func (m *Migrator) printMigrated(migrations []Migration, args Args) {
+	if args.Verbose || args.DryRun || m.structured() {
+		return
+	}
+
+	for _, migration := range migrations {
+		m.output.Println(migration.Direction.ToANSIColoredPrefix(), migration.File.Base)
+	}
+}
+
+// printSummary prints the summary event of a migration run or,
+// in verbose text output, the total time of a finished run
+func (m *Migrator) printSummary(migrations []Migration, started time.Time, args Args, err error) {
+	spent := time.Since(started).Seconds()
+	if errors.Cause(err) == ErrPendingMigrations {
+		err = nil
+	}
+
+	if !m.structured() {
+		if err == nil && args.Verbose {
+			m.output.Println(fmt.Sprintf("%sTotal migration time:%s %.4f seconds", ansi.Green, ansi.Reset, spent))
+		}
+
+		return
+	}
+
+	count := map[string]int{
+		direction.Up.ToString():   0,
+		direction.Down.ToString(): 0,
+	}
+	for _, migration := range migrations {
+		count[migration.Direction.ToString()]++
+	}
+
+	e := printer.Event{Type: printer.Summary, Schema: args.Schema, Count: count, Duration: spent}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	m.event(e)
+}
+
+// structured reports whether the output prints structured events
+func (m *Migrator) structured() bool {
+	_, ok := m.output.(printer.IEventPrinter)
+	return ok
+}
+
+// event prints e if the output prints structured events and reports whether it did
+func (m *Migrator) event(e printer.Event) bool {
+	p, ok := m.output.(printer.IEventPrinter)
+	if ok {
+		p.Event(e)
+	}
+
+	return ok
+}
+
+func (m *Migrator) chooseMigrations(files []file.File, alreadyMigrated version.Migrations, args Args) ([]file.File, error) {
+	maxMigratedVersion := alreadyMigrated.Versions().Max()
 	up := bool(args.Direction)
 
+	if !up {
+		if err := verifyDownMigrations(files, alreadyMigrated, args.Steps); err != nil {
+			return nil, err
+		}
+	}
+
 	needsMigration := make([]file.File, 0, len(files))
 	for _, f := range files {
-		_, isMigrated := alreadyMigrated[f.Version]
+		migration, isMigrated := alreadyMigrated[f.Version]
 
 		if up && isMigrated {
+			if err := verifyChecksum(f, migration, args); err != nil {
+				return nil, err
+			}
+
 			continue
 		}
 
@@ -208,3 +1122,30 @@ func (m *Migrator) chooseMigrations(files []file.File, alreadyMigrated version.V
 
 	return needsMigration, nil
 }
+
+// verifyDownMigrations fails if one of the newest steps applied versions, or any applied version if steps is 0,
+// has no down migration, instead of skipping it and migrating down an older version
+func verifyDownMigrations(files []file.File, alreadyMigrated version.Migrations, steps int) error {
+	versions := make([]int64, 0, len(alreadyMigrated))
+	for v, migration := range alreadyMigrated {
+		if migration.Name == "" {
+			versions = append(versions, v)
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+
+	if steps > 0 && len(versions) > steps {
+		versions = versions[:steps]
+	}
+
+	for _, v := range versions {
+		if file.FindByVersion(v, files) == nil {
+			return fmt.Errorf("cannot migrate down version %d, because its down migration file does not exist", v)
+		}
+	}
+
+	return nil
+}
@@ -1,10 +1,14 @@
 package migrator
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/juju/errors"
@@ -40,6 +44,17 @@ func Test_Migrator_TestSuite(t *testing.T) {
 	suite.Run(t, &MigratorTestSuite{})
 }
 
+// expectRun expects a locked run against connectionurl that finds the given migrations applied,
+// the contexts within the run may be limited by a shorter run timeout
+func (suite *MigratorTestSuite) expectRun(migrations version.Migrations) {
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.Anything).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.Anything).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+}
+
 func (suite *MigratorTestSuite) Test_New_ReturnsNewInstance_InCaseOfSuccess() {
 	// Act
 	instance := New(&driver.Mock{}, printer.New())
@@ -53,15 +68,16 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfNoUpMigrationsTo
 	// The following versions are from ../testdata.
 	// We'll mark all of them as already migrated, meaning
 	// no up migrations need to run.
-	var exists struct{}
-	migrations := version.Versions{
-		1494538273: exists,
-		1494538317: exists,
-		1494538407: exists,
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+		1494538407: {Version: 1494538407},
 	}
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
 	args := Args{
@@ -83,7 +99,7 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfNoUpMigrationsTo
 
 func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfDriverOpenError() {
 	// Arrange
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(suite.expectedErr).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(suite.expectedErr).Once()
 
 	args := Args{
 		Path:            filepath.Join("..", "testdata"),
@@ -103,7 +119,9 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfDriverOpenErro
 
 func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfDriverCreateMigrationsTableError() {
 	// Arrange
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(suite.expectedErr).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
@@ -125,9 +143,11 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfDriverCreateMi
 
 func (suite *MigratorTestSuite) Test_Migrate_ReturnsErr_InCaseOfDriverSelectMigrationsError() {
 	// Arrange
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(nil, suite.expectedErr).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(nil, suite.expectedErr).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
 	args := Args{
@@ -151,10 +171,9 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfDriverApplyMig
 	// The following versions are from ../testdata.
 	// We'll mark one of them as not migrated yet, meaning it needs
 	// to be migrated up.
-	var exists struct{}
-	migrations := version.Versions{
-		1494538273: exists,
-		1494538317: exists,
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
 	}
 
 	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
@@ -164,9 +183,11 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfDriverApplyMig
 		*file.FindByVersion(1494538407, files),
 	}
 
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), needsMigration[0], direction.Up).Return(suite.expectedErr).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
@@ -190,10 +211,9 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfUpMigrationsToRu
 	// The following versions are from ../testdata.
 	// We'll mark one of them as not migrated yet, meaning it needs
 	// to be migrated up.
-	var exists struct{}
-	migrations := version.Versions{
-		1494538273: exists,
-		1494538317: exists,
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
 	}
 
 	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
@@ -203,9 +223,11 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfUpMigrationsToRu
 		*file.FindByVersion(1494538407, files),
 	}
 
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), needsMigration[0], direction.Up).Return(nil).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
@@ -232,10 +254,12 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfNoDownMigrations
 	// The following versions are from ../testdata.
 	// We'll mark all of them as never been migrated, meaning
 	// none of them need to be migrated down.
-	migrations := make(version.Versions)
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	migrations := make(version.Migrations)
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
 	args := Args{
@@ -260,9 +284,8 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfDownMigrationsTo
 	// The following versions are from ../testdata.
 	// We'll mark one of them as migrated, meaning
 	// it needs to be migrated down.
-	var exists struct{}
-	migrations := version.Versions{
-		1494538407: exists,
+	migrations := version.Migrations{
+		1494538407: {Version: 1494538407},
 	}
 
 	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Down)
@@ -272,9 +295,11 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfDownMigrationsTo
 		*file.FindByVersion(1494538407, files),
 	}
 
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), needsMigration[0], direction.Down).Return(nil).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
@@ -320,7 +345,7 @@ func (suite *MigratorTestSuite) Test_Create_ReturnsNil_InCaseOfSuccess() {
 func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfOneUpMigrationToRun() {
 	// Arrange
 	// The following versions are from ../testdata.
-	migrations := make(version.Versions)
+	migrations := make(version.Migrations)
 
 	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
 	suite.Require().NoError(err)
@@ -329,9 +354,11 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfOneUpMigrationTo
 		*file.FindByVersion(1494538273, files),
 	}
 
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), needsMigration[0], direction.Up).Return(nil).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
@@ -356,11 +383,10 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfOneUpMigrationTo
 func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfOneDownMigrationToRun() {
 	// Arrange
 	// The following versions are from ../testdata.
-	var exists struct{}
-	migrations := version.Versions{
-		1494538273: exists,
-		1494538317: exists,
-		1494538407: exists,
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+		1494538407: {Version: 1494538407},
 	}
 
 	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Down)
@@ -370,9 +396,11 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfOneDownMigration
 		*file.FindByVersion(1494538407, files),
 	}
 
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), needsMigration[0], direction.Down).Return(nil).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
@@ -399,15 +427,16 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfUpMigrationOld
 	// The following versions are from ../testdata.
 	// We'll mark one of them as not migrated yet, meaning it needs
 	// to be migrated up.
-	var exists struct{}
-	migrations := version.Versions{
-		1494538273: exists,
-		1494538407: exists,
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538407: {Version: 1494538407},
 	}
 
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
 	args := Args{
@@ -430,10 +459,9 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNoError_InCaseOfUpMigrationO
 	// The following versions are from ../testdata.
 	// We'll mark one of them as not migrated yet, meaning it needs
 	// to be migrated up.
-	var exists struct{}
-	migrations := version.Versions{
-		1494538273: exists,
-		1494538407: exists,
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538407: {Version: 1494538407},
 	}
 
 	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
@@ -443,9 +471,11 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNoError_InCaseOfUpMigrationO
 		*file.FindByVersion(1494538317, files),
 	}
 
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), needsMigration[0], direction.Up).Return(nil).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
@@ -468,7 +498,7 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNoError_InCaseOfUpMigrationO
 func (suite *MigratorTestSuite) Test_Migrate_ReturnsNoVerboseOutput_InCaseOfVerboseFlagOff() {
 	// Arrange
 	// The following versions are from ../testdata.
-	migrations := make(version.Versions)
+	migrations := make(version.Migrations)
 
 	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
 	suite.Require().NoError(err)
@@ -477,9 +507,11 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNoVerboseOutput_InCaseOfVerb
 		*file.FindByVersion(1494538273, files),
 	}
 
-	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl").Return(nil).Once()
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
 	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
-	suite.driverMock.On("SelectAllMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
 	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), needsMigration[0], direction.Up).Return(nil).Once()
 	suite.driverMock.On("Close").Return(nil).Once()
 
@@ -501,6 +533,881 @@ func (suite *MigratorTestSuite) Test_Migrate_ReturnsNoVerboseOutput_InCaseOfVerb
 	suite.False(suite.output.Contains("seconds"))
 }
 
+func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfDriverLockError() {
+	// Arrange
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(suite.expectedErr).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:                filepath.Join("..", "testdata"),
+		URL:                 "connectionurl",
+		Direction:           direction.Up,
+		TimeoutDuration:     10 * time.Second,
+		LockTimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.EqualError(err, "locking failed: acquiring migration lock failed: failure")
+}
+
+func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfLockTimeout() {
+	// Arrange
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(suite.expectedErr).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:                filepath.Join("..", "testdata"),
+		URL:                 "connectionurl",
+		Direction:           direction.Up,
+		TimeoutDuration:     10 * time.Second,
+		LockTimeoutDuration: time.Millisecond,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.EqualError(err, "locking failed: could not acquire migration lock within 1ms, another migration may be running: failure")
+}
+
+func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfDirtyVersion() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317, Dirty: true},
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.EqualError(errors.Cause(err), "version 1494538317 is dirty, fix the database manually and run force")
+}
+
+func (suite *MigratorTestSuite) Test_Goto_MigratesDown_InCaseOfOlderTargetVersion() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+		1494538407: {Version: 1494538407},
+	}
+
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Down)
+	suite.Require().NoError(err)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	migrate407 := suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *file.FindByVersion(1494538407, files), direction.Down).Return(nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *file.FindByVersion(1494538317, files), direction.Down).Return(nil).Once().NotBefore(migrate407)
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+		Version:         1494538273,
+	}
+
+	// Act
+	err = suite.instance.Goto(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal("\x1b[31m>\x1b[0m 1494538407_replace_user_phone_with_email.down.sql\n\x1b[31m>\x1b[0m 1494538317_add_phone_number_to_users.down.sql", suite.output.String())
+}
+
+func (suite *MigratorTestSuite) Test_Goto_MigratesUp_InCaseOfNewerTargetVersion() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+	}
+
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *file.FindByVersion(1494538317, files), direction.Up).Return(nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+		Version:         1494538317,
+	}
+
+	// Act
+	err = suite.instance.Goto(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.True(suite.output.Contains("1494538317_add_phone_number_to_users.up.sql"))
+	suite.False(suite.output.Contains("1494538407"))
+}
+
+func (suite *MigratorTestSuite) Test_Goto_ReturnsError_InCaseOfMissingTargetFile() {
+	// Arrange
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+		Version:         1494538300,
+	}
+
+	// Act
+	err := suite.instance.Goto(args)
+
+	// Assert
+	suite.EqualError(err, "migration file for version 1494538300 not found")
+}
+
+func (suite *MigratorTestSuite) Test_Redo_MigratesDownAndUp_InCaseOfSuccess() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+		1494538407: {Version: 1494538407},
+	}
+
+	upFiles, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	downFiles, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Down)
+	suite.Require().NoError(err)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	down407 := suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *file.FindByVersion(1494538407, downFiles), direction.Down).Return(nil).Once()
+	down317 := suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *file.FindByVersion(1494538317, downFiles), direction.Down).Return(nil).Once().NotBefore(down407)
+	up317 := suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *file.FindByVersion(1494538317, upFiles), direction.Up).Return(nil).Once().NotBefore(down317)
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *file.FindByVersion(1494538407, upFiles), direction.Up).Return(nil).Once().NotBefore(up317)
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+		Steps:           2,
+	}
+
+	// Act
+	err = suite.instance.Redo(args)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *MigratorTestSuite) Test_Redo_ReturnsError_InCaseOfDownMigrationError() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538407: {Version: 1494538407},
+	}
+
+	downFiles, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Down)
+	suite.Require().NoError(err)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *file.FindByVersion(1494538407, downFiles), direction.Down).Return(suite.expectedErr).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+		Steps:           1,
+	}
+
+	// Act
+	err = suite.instance.Redo(args)
+
+	// Assert
+	suite.EqualError(err, "migrating down failed, no up migrations were applied: applying migration failed: 1494538407_replace_user_phone_with_email.down.sql: failure")
+}
+
+func (suite *MigratorTestSuite) Test_Force_ReturnsNil_InCaseOfSuccess() {
+	// Arrange
+	// The following versions are from ../testdata.
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	f := file.FindByVersion(1494538317, files)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Force", mock.AnythingOfType("*context.timerCtx"), *f, direction.Up).Return(nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		Version:         1494538317,
+	}
+
+	// Act
+	err = suite.instance.Force(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.True(suite.output.Contains("Forced version 1494538317 up"))
+}
+
+func (suite *MigratorTestSuite) Test_Force_ReturnsNil_InCaseOfDownWithoutFile() {
+	// Arrange
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Force", mock.AnythingOfType("*context.timerCtx"), file.File{Version: 1494538500}, direction.Down).Return(nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Down,
+		TimeoutDuration: 10 * time.Second,
+		Version:         1494538500,
+	}
+
+	// Act
+	err := suite.instance.Force(args)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *MigratorTestSuite) Test_Force_ReturnsError_InCaseOfUpWithoutFile() {
+	// Arrange
+	args := Args{
+		Path:      filepath.Join("..", "testdata"),
+		URL:       "connectionurl",
+		Direction: direction.Up,
+		Version:   1494538500,
+	}
+
+	// Act
+	err := suite.instance.Force(args)
+
+	// Assert
+	suite.EqualError(err, "migration file for version 1494538500 not found")
+}
+
+func (suite *MigratorTestSuite) Test_Migrate_ReturnsErrPendingMigrations_InCaseOfDryRun() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		DryRun:          true,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.Equal(ErrPendingMigrations, errors.Cause(err))
+	suite.True(suite.output.Contains("1494538407_replace_user_phone_with_email.up.sql"))
+	suite.True(suite.output.Contains("alter table users add column email text;"))
+}
+
+func (suite *MigratorTestSuite) Test_Migrate_PrintsOnlyFileNames_InCaseOfQuietDryRun() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Down,
+		Steps:           1,
+		TimeoutDuration: 10 * time.Second,
+		DryRun:          true,
+		Quiet:           true,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.Equal(ErrPendingMigrations, errors.Cause(err))
+	suite.Equal("\x1b[31m>\x1b[0m 1494538317_add_phone_number_to_users.down.sql", suite.output.String())
+}
+
+func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfDryRunWithNothingToMigrate() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+		1494538407: {Version: 1494538407},
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		DryRun:          true,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *MigratorTestSuite) Test_Migrate_PlansOnlyPendingMigrations_InCaseOfDryRunWithLegacyMigrationsTable() {
+	// Arrange
+	// The following versions are from ../testdata, tables of older versions have no checksums.
+	appliedAt := time.Date(2017, 5, 11, 21, 31, 13, 0, time.UTC)
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273, AppliedAt: &appliedAt},
+		1494538317: {Version: 1494538317, AppliedAt: &appliedAt},
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		DryRun:          true,
+		Quiet:           true,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.Equal(ErrPendingMigrations, errors.Cause(err))
+	suite.False(suite.output.Contains("1494538273_create_table_users.up.sql"))
+	suite.False(suite.output.Contains("1494538317_add_phone_number_to_users.up.sql"))
+	suite.True(suite.output.Contains("1494538407_replace_user_phone_with_email.up.sql"))
+}
+
+func (suite *MigratorTestSuite) Test_Migrate_DoesNotCreateMigrationsTable_InCaseOfDryRunWithoutMigrationsTable() {
+	// Arrange
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(nil, driver.ErrNoMigrationsTable).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		DryRun:          true,
+		Quiet:           true,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.Equal(ErrPendingMigrations, errors.Cause(err))
+	suite.True(suite.output.Contains("1494538273_create_table_users.up.sql"))
+	suite.True(suite.output.Contains("1494538407_replace_user_phone_with_email.up.sql"))
+	suite.driverMock.AssertNotCalled(suite.T(), "CreateMigrationsTable", mock.Anything)
+}
+
+func (suite *MigratorTestSuite) Test_Script_WritesScriptFile_InCaseOfSuccess() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+	}
+
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Script", *file.FindByVersion(1494538317, files), direction.Up).Return("-- 1494538317\n").Once()
+	suite.driverMock.On("Script", *file.FindByVersion(1494538407, files), direction.Up).Return("-- 1494538407\n").Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	output := filepath.Join(suite.T().TempDir(), "script.sql")
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+		Output:          output,
+	}
+
+	// Act
+	scripted, err := suite.instance.Script(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(scripted, 2)
+	b, err := os.ReadFile(output)
+	suite.Require().NoError(err)
+	suite.Equal("\\set ON_ERROR_STOP on\n\n-- 1494538317\n\n-- 1494538407\n", string(b))
+}
+
+func (suite *MigratorTestSuite) Test_Script_WritesMigrationsTable_InCaseOfMissingMigrationsTable() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+	}
+
+	files, err := file.ListFilesFS(fsys, direction.Up)
+	suite.Require().NoError(err)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(nil, driver.ErrNoMigrationsTable).Once()
+	suite.driverMock.On("MigrationsTableScript").Return("-- schema_migrations\n").Once()
+	suite.driverMock.On("Script", files[0], direction.Up).Return("-- 1\n").Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	scripted, err := instance.ScriptContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(scripted, 1)
+	suite.Equal("\\set ON_ERROR_STOP on\n\n-- schema_migrations\n\n-- 1\n", suite.output.String())
+	suite.driverMock.AssertNotCalled(suite.T(), "CreateMigrationsTable", mock.Anything)
+}
+
+func (suite *MigratorTestSuite) Test_NewWithFS_ReadsMigrationsFromFS_InCaseOfSuccess() {
+	// Arrange
+	instance := NewWithFS(suite.driverMock, suite.output, os.DirFS(filepath.Join("..", "testdata")))
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(make(version.Migrations), nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	statuses, err := instance.Status(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(3, statuses.Count(Pending))
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_ReturnsResult_InCaseOfUpMigrationsToRun() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+	}
+
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	f := file.FindByVersion(1494538407, files)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *f, direction.Up).Return(nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	result, err := suite.instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	if suite.NotNil(result) && suite.Len(result.Migrations, 1) {
+		suite.Equal(*f, result.Migrations[0].File)
+		suite.Equal(direction.Up, result.Migrations[0].Direction)
+		suite.True(result.Duration >= result.Migrations[0].Duration)
+	}
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_ReturnsError_InCaseOfCanceledContext() {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Run(func(args mock.Arguments) {
+		suite.Error(args.Get(0).(context.Context).Err())
+	}).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(context.Canceled).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:                        filepath.Join("..", "testdata"),
+		URL:                         "connectionurl",
+		Direction:                   direction.Up,
+		TimeoutDuration:             10 * time.Second,
+		DBConnectionTimeoutDuration: 10 * time.Second,
+		LockTimeoutDuration:         10 * time.Second,
+	}
+
+	// Act
+	result, err := suite.instance.MigrateContext(ctx, args)
+
+	// Assert
+	suite.EqualError(err, "locking failed: acquiring migration lock failed: context canceled")
+	suite.Nil(result)
+}
+
+func (suite *MigratorTestSuite) Test_Status_ReturnsStatuses_InCaseOfSuccess() {
+	// Arrange
+	// The following versions are from ../testdata, except 1494538500
+	// which has no migration file and is therefore orphaned.
+	appliedAt := time.Date(2017, 5, 11, 21, 4, 33, 0, time.UTC)
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273, AppliedAt: &appliedAt},
+		1494538500: {Version: 1494538500, AppliedAt: &appliedAt},
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	statuses, err := suite.instance.Status(args)
+
+	// Assert
+	suite.NoError(err)
+	if suite.Len(statuses, 4) {
+		suite.Equal(Status{Version: 1494538273, Base: "1494538273_create_table_users.up.sql", State: Applied, AppliedAt: &appliedAt}, statuses[0])
+		suite.Equal(Status{Version: 1494538317, Base: "1494538317_add_phone_number_to_users.up.sql", State: Pending}, statuses[1])
+		suite.Equal(Status{Version: 1494538407, Base: "1494538407_replace_user_phone_with_email.up.sql", State: Pending}, statuses[2])
+		suite.Equal(Status{Version: 1494538500, State: Orphaned, AppliedAt: &appliedAt}, statuses[3])
+	}
+
+	suite.Equal(1, statuses.Count(Applied))
+	suite.Equal(2, statuses.Count(Pending))
+	suite.Equal(1, statuses.Count(Orphaned))
+	suite.True(suite.output.Contains("1494538317_add_phone_number_to_users.up.sql"))
+	suite.True(suite.output.Contains("2017-05-11 21:04:33"))
+}
+
+func (suite *MigratorTestSuite) Test_Status_ReturnsPendingStatuses_InCaseOfMissingMigrationsTable() {
+	// Arrange
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(nil, driver.ErrNoMigrationsTable).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	statuses, err := suite.instance.Status(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(statuses, 3)
+	suite.Equal(3, statuses.Count(Pending))
+	suite.driverMock.AssertNotCalled(suite.T(), "CreateMigrationsTable", mock.Anything)
+}
+
+func (suite *MigratorTestSuite) Test_Status_ReturnsError_InCaseOfDriverSelectMigrationsError() {
+	// Arrange
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(nil, suite.expectedErr).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	statuses, err := suite.instance.Status(args)
+
+	// Assert
+	suite.EqualError(err, "selecting existing migrations failed: failure")
+	suite.Nil(statuses)
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_PrintsEvents_InCaseOfStructuredOutput() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+	}
+
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	f := file.FindByVersion(1494538407, files)
+
+	var b bytes.Buffer
+	suite.instance.SetOutput(printer.NewJSON(&b))
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *f, direction.Up).Return(nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	_, err = suite.instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+
+	var events []printer.Event
+	decoder := json.NewDecoder(&b)
+	for decoder.More() {
+		var e printer.Event
+		suite.Require().NoError(decoder.Decode(&e))
+		events = append(events, e)
+	}
+
+	if suite.Len(events, 3) {
+		suite.Equal(printer.Started, events[0].Type)
+		suite.Equal(f.Base, events[0].File)
+		suite.Equal(f.Version, events[0].Version)
+		suite.Equal("up", events[0].Direction)
+		suite.Equal(printer.Finished, events[1].Type)
+		suite.Equal(f.Base, events[1].File)
+		suite.Equal(printer.Summary, events[2].Type)
+		suite.Equal(map[string]int{"up": 1, "down": 0}, events[2].Count)
+		suite.Empty(events[2].Error)
+	}
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_ReturnsErrInterrupted_InCaseOfContextCanceledWhileMigrating() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+	}
+
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	f := file.FindByVersion(1494538317, files)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), *f, direction.Up).Return(context.Canceled).Run(func(mock.Arguments) {
+		cancel()
+	}).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	result, err := suite.instance.MigrateContext(ctx, args)
+
+	// Assert
+	suite.Equal(ErrInterrupted, errors.Cause(err))
+	suite.Nil(result)
+	suite.True(suite.output.Contains("Interrupted " + f.Base))
+}
+
+func (suite *MigratorTestSuite) Test_Migrate_ReturnsError_InCaseOfChecksumMismatch() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273, Checksum: "changed"},
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.EqualError(errors.Cause(err), "checksum of 1494538273_create_table_users.up.sql does not match already migrated version 1494538273, run repair if the change was intentional")
+}
+
+func (suite *MigratorTestSuite) Test_Migrate_ReturnsNil_InCaseOfChecksumMismatchButNoChecksum() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273, Checksum: "changed"},
+		1494538317: {Version: 1494538317, Checksum: "changed"},
+		1494538407: {Version: 1494538407, Checksum: "changed"},
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		NoChecksum:      true,
+	}
+
+	// Act
+	err := suite.instance.Migrate(args)
+
+	// Assert
+	suite.NoError(err)
+}
+
+func (suite *MigratorTestSuite) Test_Repair_ReturnsRepairedFiles_InCaseOfChecksumMismatch() {
+	// Arrange
+	// The following versions are from ../testdata.
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	unchanged := file.FindByVersion(1494538317, files)
+	changed := file.FindByVersion(1494538273, files)
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273, Checksum: "changed"},
+		1494538317: {Version: 1494538317, Checksum: unchanged.Checksum()},
+	}
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("UpdateChecksum", mock.AnythingOfType("*context.timerCtx"), *changed).Return(nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	repaired, err := suite.instance.Repair(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal([]file.File{*changed}, repaired)
+	suite.True(suite.output.Contains("1494538273_create_table_users.up.sql"))
+}
+
 // private
 
 func remove(filename string) {
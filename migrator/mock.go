@@ -1,8 +1,12 @@
 package migrator
 
 import (
+	"context"
+
 	"github.com/stretchr/testify/mock"
 	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/lint"
+	"github.com/wallester/migrate/version"
 )
 
 // Mock is mock object for Migrator
@@ -18,6 +22,16 @@ func (m *Mock) Migrate(a Args) error {
 	return args.Error(0)
 }
 
+// MigrateContext is a mock method
+func (m *Mock) MigrateContext(ctx context.Context, a Args) (*Result, error) {
+	args := m.Called(ctx, a)
+	if args.Get(0) != nil {
+		return args.Get(0).(*Result), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
 // Create is a mock method
 func (m *Mock) Create(name, path string, verbose bool) (*file.Pair, error) {
 	args := m.Called(name, path, verbose)
@@ -27,3 +41,103 @@ func (m *Mock) Create(name, path string, verbose bool) (*file.Pair, error) {
 
 	return nil, args.Error(1)
 }
+
+// Status is a mock method
+func (m *Mock) Status(a Args) (Statuses, error) {
+	args := m.Called(a)
+	if args.Get(0) != nil {
+		return args.Get(0).(Statuses), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+// History is a mock method
+func (m *Mock) History(a Args) ([]version.HistoryEntry, error) {
+	args := m.Called(a)
+	if args.Get(0) != nil {
+		return args.Get(0).([]version.HistoryEntry), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+// Lint is a mock method
+func (m *Mock) Lint(a Args) ([]lint.Finding, error) {
+	args := m.Called(a)
+	if args.Get(0) != nil {
+		return args.Get(0).([]lint.Finding), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+// Validate is a mock method
+func (m *Mock) Validate(a Args) ([]file.Problem, error) {
+	args := m.Called(a)
+	if args.Get(0) != nil {
+		return args.Get(0).([]file.Problem), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+// Repair is a mock method
+func (m *Mock) Repair(a Args) ([]file.File, error) {
+	args := m.Called(a)
+	if args.Get(0) != nil {
+		return args.Get(0).([]file.File), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+// Force is a mock method
+func (m *Mock) Force(a Args) error {
+	args := m.Called(a)
+	return args.Error(0)
+}
+
+// Goto is a mock method
+func (m *Mock) Goto(a Args) error {
+	args := m.Called(a)
+	return args.Error(0)
+}
+
+// GotoContext is a mock method
+func (m *Mock) GotoContext(ctx context.Context, a Args) error {
+	args := m.Called(ctx, a)
+	return args.Error(0)
+}
+
+// Redo is a mock method
+func (m *Mock) Redo(a Args) error {
+	args := m.Called(a)
+	return args.Error(0)
+}
+
+// RedoContext is a mock method
+func (m *Mock) RedoContext(ctx context.Context, a Args) error {
+	args := m.Called(ctx, a)
+	return args.Error(0)
+}
+
+// Script is a mock method
+func (m *Mock) Script(a Args) ([]file.File, error) {
+	args := m.Called(a)
+	if args.Get(0) != nil {
+		return args.Get(0).([]file.File), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
+// ScriptContext is a mock method
+func (m *Mock) ScriptContext(ctx context.Context, a Args) ([]file.File, error) {
+	args := m.Called(ctx, a)
+	if args.Get(0) != nil {
+		return args.Get(0).([]file.File), args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
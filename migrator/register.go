@@ -0,0 +1,87 @@
+package migrator
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/file"
+)
+
+// Register registers Go functions that migrate the version up and down within the transaction of the migration,
+// down may be nil. It is meant to be called from init functions, the migration is named after the version and the caller's file.
+// Register panics if the version is registered twice.
+func Register(v int64, up, down file.Func) {
+	if up == nil {
+		panic(fmt.Sprintf("up function of Go migration %d is nil", v))
+	}
+
+	base := fmt.Sprintf("%d.go", v)
+	if _, caller, _, ok := runtime.Caller(1); ok {
+		base = fmt.Sprintf("%d_%s", v, filepath.Base(caller))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exists := registry.migrations[v]; exists {
+		panic(fmt.Sprintf("Go migration %d is already registered", v))
+	}
+
+	registry.migrations[v] = goMigration{base: base, up: up, down: down}
+}
+
+// private
+
+type goMigration struct {
+	base string
+	up   file.Func
+	down file.Func
+}
+
+var registry = struct {
+	sync.Mutex
+	migrations map[int64]goMigration
+}{
+	migrations: make(map[int64]goMigration),
+}
+
+// withGoMigrations merges the registered Go migrations of the direction into the files, ordered by version
+func withGoMigrations(files []file.File, d direction.Direction) ([]file.File, error) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if len(registry.migrations) == 0 {
+		return files, nil
+	}
+
+	for v, migration := range registry.migrations {
+		fn := migration.up
+		if d == direction.Down {
+			fn = migration.down
+		}
+
+		if fn == nil {
+			continue
+		}
+
+		if f := file.FindByVersion(v, files); f != nil {
+			return nil, fmt.Errorf("version %d is registered as Go migration %s and exists as %s", v, migration.base, f.Base)
+		}
+
+		files = append(files, file.File{Base: migration.base, Version: v, Func: fn})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if d == direction.Up {
+			return files[i].Version < files[j].Version
+		}
+
+		return files[i].Version > files[j].Version
+	})
+
+	return files, nil
+}
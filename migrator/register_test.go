@@ -0,0 +1,183 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/version"
+)
+
+func (suite *MigratorTestSuite) Test_MigrateContext_RunsGoMigrationsInVersionOrder_InCaseOfRegisteredFunctions() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+		"3_add_index.up.sql":    {Data: []byte("create index t_id_idx on t(id);")},
+	}
+
+	up := func(ctx context.Context, tx *sql.Tx) error {
+		return nil
+	}
+
+	Register(2, up, nil)
+	defer unregister(2)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	var migrated []string
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(version.Migrations{1: {Version: 1}}, nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), mock.AnythingOfType("file.File"), direction.Up).Run(func(args mock.Arguments) {
+		f := args.Get(1).(file.File)
+		migrated = append(migrated, f.Base)
+		if f.Version == 2 {
+			suite.NotNil(f.Func)
+		}
+	}).Return(nil).Twice()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	_, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal([]string{"2_register_test.go", "3_add_index.up.sql"}, migrated)
+}
+
+func (suite *MigratorTestSuite) Test_Status_ReturnsError_InCaseOfGoMigrationVersionConflict() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+	}
+
+	noop := func(ctx context.Context, tx *sql.Tx) error {
+		return nil
+	}
+
+	Register(1, noop, noop)
+	defer unregister(1)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	// Act
+	statuses, err := instance.Status(Args{URL: "connectionurl"})
+
+	// Assert
+	suite.EqualError(err, "listing migration files failed: version 1 is registered as Go migration 1_register_test.go and exists as 1_create_table.up.sql")
+	suite.Nil(statuses)
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_ReturnsError_InCaseOfNewestGoMigrationWithoutDown() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql":   {Data: []byte("create table t(id int);")},
+		"1_create_table.down.sql": {Data: []byte("drop table t;")},
+	}
+
+	noop := func(ctx context.Context, tx *sql.Tx) error {
+		return nil
+	}
+
+	Register(2, noop, nil)
+	defer unregister(2)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(version.Migrations{1: {Version: 1}, 2: {Version: 2}}, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		Direction:       direction.Down,
+		Steps:           1,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	migrations, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.EqualError(err, "migrating failed: choosing migrations failed: cannot migrate down version 2, because its down migration file does not exist")
+	suite.Empty(migrations)
+	suite.driverMock.AssertNotCalled(suite.T(), "Migrate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *MigratorTestSuite) Test_Redo_ReturnsError_InCaseOfNewestGoMigrationWithoutDown() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql":   {Data: []byte("create table t(id int);")},
+		"1_create_table.down.sql": {Data: []byte("drop table t;")},
+	}
+
+	noop := func(ctx context.Context, tx *sql.Tx) error {
+		return nil
+	}
+
+	Register(2, noop, nil)
+	defer unregister(2)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(version.Migrations{1: {Version: 1}, 2: {Version: 2}}, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		Steps:           1,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	err := instance.Redo(args)
+
+	// Assert
+	suite.EqualError(err, "migrating failed: choosing migrations failed: cannot migrate down version 2, because its down migration file does not exist")
+	suite.driverMock.AssertNotCalled(suite.T(), "Migrate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *MigratorTestSuite) Test_Register_Panics_InCaseOfDuplicateVersion() {
+	// Arrange
+	noop := func(ctx context.Context, tx *sql.Tx) error {
+		return nil
+	}
+
+	Register(4, noop, nil)
+	defer unregister(4)
+
+	// Act
+	register := func() {
+		Register(4, noop, nil)
+	}
+
+	// Assert
+	suite.PanicsWithValue("Go migration 4 is already registered", register)
+}
+
+func unregister(v int64) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	delete(registry.migrations, v)
+}
@@ -0,0 +1,55 @@
+package migrator
+
+import (
+	"fmt"
+
+	"github.com/juju/errors"
+	"github.com/mgutz/ansi"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/version"
+)
+
+// private
+
+// repeatsAll returns true if the run applies all up migrations, which is when repeatable migrations run
+func repeatsAll(args Args) bool {
+	return args.Direction == direction.Up && args.Steps == 0
+}
+
+func (m *Migrator) listRepeatableFiles(args Args) ([]file.File, error) {
+	if m.source != nil {
+		return m.source.ListRepeatableFiles()
+	}
+
+	return file.NewDir(args.Path).ListRepeatableFiles()
+}
+
+// chooseRepeatableMigrations returns the repeatable migrations whose checksum differs from the last applied one
+func (m *Migrator) chooseRepeatableMigrations(alreadyMigrated version.Migrations, args Args) ([]file.File, error) {
+	files, err := m.listRepeatableFiles(args)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing repeatable migration files failed")
+	}
+
+	needsMigration := changedRepeatableMigrations(files, alreadyMigrated)
+	if len(needsMigration) > 0 && args.Verbose {
+		m.output.Println(fmt.Sprintf("%sRepeatable files to be migrated:%s %d", ansi.Yellow, ansi.Reset, len(needsMigration)))
+	}
+
+	return needsMigration, nil
+}
+
+// changedRepeatableMigrations returns the files that have not been applied or have changed since
+func changedRepeatableMigrations(files []file.File, alreadyMigrated version.Migrations) []file.File {
+	needsMigration := make([]file.File, 0, len(files))
+	for _, f := range files {
+		if migration, isMigrated := alreadyMigrated[f.Version]; isMigrated && migration.Checksum == f.Checksum() {
+			continue
+		}
+
+		needsMigration = append(needsMigration, f)
+	}
+
+	return needsMigration
+}
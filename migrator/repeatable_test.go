@@ -0,0 +1,129 @@
+package migrator
+
+import (
+	"context"
+	"testing/fstest"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/version"
+)
+
+func (suite *MigratorTestSuite) Test_MigrateContext_AppliesChangedRepeatableMigrations_InCaseOfUpMigration() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+		"R_functions.sql":       {Data: []byte("create or replace function f() returns int as 'select 1' language sql;")},
+		"R_views.sql":           {Data: []byte("create or replace view v as select id from t;")},
+	}
+
+	repeatables, err := file.ListRepeatableFilesFS(fsys)
+	suite.Require().NoError(err)
+
+	functions, views := repeatables[0], repeatables[1]
+
+	// The functions are unchanged since they were applied, the view has changed.
+	migrations := version.Migrations{
+		1:                 {Version: 1},
+		functions.Version: {Version: functions.Version, Name: functions.Base, Checksum: functions.Checksum()},
+		views.Version:     {Version: views.Version, Name: views.Base, Checksum: "changed"},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.expectRun(migrations)
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), views, direction.Up).Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	result, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	if suite.Len(result.Migrations, 1) {
+		suite.Equal(views, result.Migrations[0].File)
+	}
+
+	suite.True(suite.output.Contains("R_views.sql"))
+	suite.False(suite.output.Contains("R_functions.sql"))
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_SkipsRepeatableMigrations_InCaseOfSteps() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+		"R_views.sql":           {Data: []byte("create or replace view v as select id from t;")},
+	}
+
+	upFiles, err := file.ListFilesFS(fsys, direction.Up)
+	suite.Require().NoError(err)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.expectRun(version.Migrations{})
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), upFiles[0], direction.Up).Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		Steps:           1,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	result, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(errors.Cause(err))
+	if suite.Len(result.Migrations, 1) {
+		suite.Equal(upFiles[0], result.Migrations[0].File)
+	}
+}
+
+func (suite *MigratorTestSuite) Test_Status_ReturnsRepeatableStatuses_InCaseOfChangedFile() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+		"R_views.sql":           {Data: []byte("create or replace view v as select id from t;")},
+	}
+
+	views := file.RepeatableVersion("R_views.sql")
+	removed := file.RepeatableVersion("R_removed.sql")
+	migrations := version.Migrations{
+		1:       {Version: 1},
+		views:   {Version: views, Name: "R_views.sql", Checksum: "changed"},
+		removed: {Version: removed, Name: "R_removed.sql", Checksum: "removed"},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	statuses, err := instance.Status(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal(Statuses{
+		{Version: 1, Base: "1_create_table.up.sql", State: Applied},
+		{Version: removed, Base: "R_removed.sql", Repeatable: true, State: Orphaned},
+		{Version: views, Base: "R_views.sql", Repeatable: true, State: Pending},
+	}, statuses)
+	suite.True(suite.output.Contains("R " + Pending.ToANSIColoredString() + " - R_views.sql"))
+}
@@ -0,0 +1,69 @@
+package migrator
+
+import (
+	"time"
+
+	"github.com/mgutz/ansi"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/file"
+)
+
+// Result represents a result of a migration run
+type Result struct {
+	Migrations []Migration
+	Schemas    []SchemaResult
+	Targets    []TargetResult
+	Duration   time.Duration
+}
+
+// Outcome represents an outcome of migrating a schema or a database
+type Outcome string
+
+const (
+	// Succeeded means all migrations have been applied.
+	Succeeded Outcome = "succeeded"
+	// Current means there was nothing to migrate.
+	Current Outcome = "current"
+	// Failed means migrating failed.
+	Failed Outcome = "failed"
+	// Skipped means the schema was not migrated because an earlier one failed.
+	Skipped Outcome = "skipped"
+)
+
+// ToANSIColoredString returns the colored string representation of the outcome.
+func (s Outcome) ToANSIColoredString() string {
+	switch s {
+	case Succeeded, Current:
+		return ansi.Green + string(s) + ansi.Reset
+	case Skipped:
+		return ansi.Yellow + string(s) + ansi.Reset
+	default:
+		return ansi.Red + string(s) + ansi.Reset
+	}
+}
+
+// SchemaResult represents a result of migrating one schema
+type SchemaResult struct {
+	Schema     string
+	State      Outcome
+	Migrations []Migration
+	Duration   time.Duration
+	Err        error
+}
+
+// TargetResult represents a result of migrating one of many databases
+type TargetResult struct {
+	Target     string
+	State      Outcome
+	Migrations []Migration
+	Schemas    []SchemaResult
+	Duration   time.Duration
+	Err        error
+}
+
+// Migration represents a migrated file
+type Migration struct {
+	File      file.File
+	Direction direction.Direction
+	Duration  time.Duration
+}
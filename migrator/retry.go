@@ -0,0 +1,103 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/printer"
+)
+
+// defaultRetryDelay is the delay before the first retry unless RetryDelayDuration is set
+const defaultRetryDelay = time.Second
+
+// maxRetryDelay caps the exponential backoff between attempts of a migration
+const maxRetryDelay = time.Minute
+
+// private
+
+// migrateWithRetry migrates the file and retries its transaction with exponential backoff and jitter
+// after retryable errors until the maximum number of attempts is reached
+func (m *Migrator) migrateWithRetry(ctx context.Context, f file.File, d direction.Direction, args Args) error {
+	for attempt := 1; ; attempt++ {
+		err := m.migrateFile(ctx, f, d, args)
+		if err == nil || attempt >= args.MaxAttempts || !driver.IsRetryable(err) {
+			return err
+		}
+
+		base := args.RetryDelayDuration
+		if base <= 0 {
+			base = defaultRetryDelay
+		}
+
+		delay := retryDelay(base, attempt)
+		e := printer.Event{
+			Type:      printer.Retrying,
+			Schema:    args.Schema,
+			File:      f.Base,
+			Version:   f.Version,
+			Direction: d.ToString(),
+			Attempt:   attempt + 1,
+			Duration:  delay.Seconds(),
+			Error:     err.Error(),
+		}
+		if !m.event(e) {
+			m.output.Println(
+				fmt.Sprintf(
+					"%s Retrying %s in %s (attempt %d of %d): %s",
+					d.ToANSIColoredPrefix(),
+					f.Base,
+					delay.Round(time.Millisecond),
+					attempt+1,
+					args.MaxAttempts,
+					err,
+				),
+			)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// migrateFile migrates the file within its timeout
+func (m *Migrator) migrateFile(ctx context.Context, f file.File, d direction.Direction, args Args) error {
+	migrationCtx, cancel := context.WithTimeout(ctx, migrationTimeout(f, args))
+	defer cancel()
+
+	err := m.db.Migrate(migrationCtx, f, d)
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case args.RunTimeoutDuration > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return errors.Annotatef(err, "run timed out after %s", args.RunTimeoutDuration)
+	case errors.Is(migrationCtx.Err(), context.DeadlineExceeded):
+		return errors.Annotatef(err, "timed out after %s", migrationTimeout(f, args))
+	}
+
+	return err
+}
+
+// retryDelay returns the exponential backoff of the attempt, capped at maxRetryDelay,
+// with a random jitter of up to half of it
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	delay := maxRetryDelay
+	if attempt < 32 && base < maxRetryDelay>>uint(attempt-1) {
+		delay = base << uint(attempt-1)
+	}
+
+	half := delay / 2
+	return delay - half + time.Duration(rand.Int63n(int64(half)+1)) // #nosec G404 -- jitter is not security sensitive
+}
@@ -0,0 +1,113 @@
+package migrator
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/version"
+)
+
+func (suite *MigratorTestSuite) Test_MigrateContext_RetriesMigration_InCaseOfRetryableError() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.expectRun(version.Migrations{})
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), mock.AnythingOfType("file.File"), direction.Up).Return(&driver.RetryableError{Err: suite.expectedErr}).Twice()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), mock.AnythingOfType("file.File"), direction.Up).Return(nil).Once()
+
+	args := Args{
+		URL:                "connectionurl",
+		Direction:          direction.Up,
+		TimeoutDuration:    time.Second,
+		MaxAttempts:        3,
+		RetryDelayDuration: time.Millisecond,
+	}
+
+	// Act
+	result, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(result.Migrations, 1)
+	suite.True(suite.output.Contains("Retrying 1_create_table.up.sql"))
+	suite.True(suite.output.Contains("(attempt 3 of 3): failure"))
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_ReturnsError_InCaseOfRetryableErrorOnLastAttempt() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.expectRun(version.Migrations{})
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), mock.AnythingOfType("file.File"), direction.Up).Return(&driver.RetryableError{Err: suite.expectedErr}).Twice()
+
+	args := Args{
+		URL:                "connectionurl",
+		Direction:          direction.Up,
+		TimeoutDuration:    time.Second,
+		MaxAttempts:        2,
+		RetryDelayDuration: time.Millisecond,
+	}
+
+	// Act
+	_, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.Error(err)
+	suite.True(driver.IsRetryable(err))
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_DoesNotRetryMigration_InCaseOfOtherError() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.expectRun(version.Migrations{})
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), mock.AnythingOfType("file.File"), direction.Up).Return(suite.expectedErr).Once()
+
+	args := Args{
+		URL:                "connectionurl",
+		Direction:          direction.Up,
+		TimeoutDuration:    time.Second,
+		MaxAttempts:        3,
+		RetryDelayDuration: time.Millisecond,
+	}
+
+	// Act
+	_, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.Error(err)
+	suite.False(suite.output.Contains("Retrying"))
+}
+
+func Test_retryDelay_ReturnsExponentialDelayWithJitter(t *testing.T) {
+	for attempt, expected := range map[int]time.Duration{
+		1:  time.Second,
+		3:  4 * time.Second,
+		7:  maxRetryDelay,
+		64: maxRetryDelay,
+	} {
+		// Act
+		delay := retryDelay(time.Second, attempt)
+
+		// Assert
+		assert.True(t, delay >= expected/2 && delay <= expected, "attempt %d: %s", attempt, delay)
+	}
+}
@@ -0,0 +1,165 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/mgutz/ansi"
+	"github.com/wallester/migrate/printer"
+)
+
+// migrateSchemas migrates every schema with search_path set to it and its own migrations table,
+// stopping on the first failure unless ContinueOnError is set
+func (m *Migrator) migrateSchemas(ctx context.Context, args Args) (*Result, error) {
+	started := time.Now()
+
+	schemas, err := m.listSchemas(ctx, args)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing schemas failed")
+	}
+
+	result := &Result{
+		Schemas: make([]SchemaResult, 0, len(schemas)),
+	}
+
+	var failed, pending int
+	var interruptedErr error
+	for _, schema := range schemas {
+		if interruptedErr != nil || (failed > 0 && !args.ContinueOnError) {
+			result.Schemas = append(result.Schemas, SchemaResult{Schema: schema, State: Skipped})
+			continue
+		}
+
+		schemaArgs := args
+		schemaArgs.Schemas = nil
+		schemaArgs.SchemaPattern = ""
+		schemaArgs.Schema = schema
+		schemaArgs.SearchPath = true
+
+		if !m.structured() {
+			m.output.Println(fmt.Sprintf("%sSchema:%s %s", ansi.Yellow, ansi.Reset, schema))
+		}
+
+		schemaStarted := time.Now()
+		migrations, err := m.migrate(ctx, schemaArgs)
+		m.printSummary(migrations, schemaStarted, schemaArgs, err)
+
+		r := SchemaResult{
+			Schema:     schema,
+			State:      outcome(migrations, err),
+			Migrations: migrations,
+			Duration:   time.Since(schemaStarted),
+		}
+
+		switch cause := errors.Cause(err); {
+		case err == nil:
+		case cause == ErrPendingMigrations:
+			pending++
+		case cause == ErrInterrupted:
+			r.Err = err
+			interruptedErr = err
+			failed++
+		default:
+			r.Err = err
+			failed++
+		}
+
+		result.Migrations = append(result.Migrations, migrations...)
+		result.Schemas = append(result.Schemas, r)
+	}
+
+	result.Duration = time.Since(started)
+	m.printSchemaResults(result.Schemas)
+
+	if interruptedErr != nil {
+		return result, errors.Annotate(interruptedErr, "migrating schemas failed")
+	}
+
+	if failed > 0 {
+		return result, fmt.Errorf("migrating %d of %d schema(s) failed", failed, len(schemas))
+	}
+
+	if pending > 0 {
+		return result, ErrPendingMigrations
+	}
+
+	return result, nil
+}
+
+// listSchemas returns the given schemas or the ones matching the schema pattern
+func (m *Migrator) listSchemas(ctx context.Context, args Args) ([]string, error) {
+	if args.SchemaPattern == "" {
+		return args.Schemas, nil
+	}
+
+	if err := m.open(ctx, args); err != nil {
+		return nil, err
+	}
+
+	defer m.close()
+
+	ctx, cancel := context.WithTimeout(ctx, args.TimeoutDuration)
+	defer cancel()
+
+	schemas, err := m.db.SelectSchemas(ctx, args.SchemaPattern)
+	if err != nil {
+		return nil, errors.Annotate(err, "selecting schemas failed")
+	}
+
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no schemas match %s", args.SchemaPattern)
+	}
+
+	return schemas, nil
+}
+
+// outcome returns the outcome of a migration run
+func outcome(migrations []Migration, err error) Outcome {
+	switch {
+	case err != nil && errors.Cause(err) != ErrPendingMigrations:
+		return Failed
+	case len(migrations) == 0:
+		return Current
+	default:
+		return Succeeded
+	}
+}
+
+func (m *Migrator) printSchemaResults(results []SchemaResult) {
+	count := map[string]int{
+		string(Succeeded): 0,
+		string(Current):   0,
+		string(Failed):    0,
+		string(Skipped):   0,
+	}
+
+	if !m.structured() {
+		m.output.Println(fmt.Sprintf("%sSchema results:%s", ansi.Yellow, ansi.Reset))
+	}
+
+	for _, r := range results {
+		count[string(r.State)]++
+
+		e := printer.Event{
+			Type:     printer.SchemaMigrated,
+			Schema:   r.Schema,
+			State:    string(r.State),
+			Count:    map[string]int{"migrations": len(r.Migrations)},
+			Duration: r.Duration.Seconds(),
+		}
+
+		message := "-"
+		if r.Err != nil {
+			e.Error = r.Err.Error()
+			message = e.Error
+		}
+
+		if !m.event(e) {
+			m.output.Println(r.Schema, r.State.ToANSIColoredString(), len(r.Migrations), "migration(s)", message)
+		}
+	}
+
+	m.event(printer.Event{Type: printer.Summary, Count: count})
+}
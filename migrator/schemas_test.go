@@ -0,0 +1,163 @@
+package migrator
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/version"
+)
+
+func (suite *MigratorTestSuite) Test_MigrateContext_SkipsRemainingSchemas_InCaseOfSchemaFailure() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+	}
+
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	f := file.FindByVersion(1494538407, files)
+
+	suite.expectSchemaMigration("tenant_1", migrations, *f, suite.expectedErr)
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		Schemas:         []string{"tenant_1", "tenant_2"},
+	}
+
+	// Act
+	result, err := suite.instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.EqualError(err, "migrating 1 of 2 schema(s) failed")
+	if suite.NotNil(result) && suite.Len(result.Schemas, 2) {
+		suite.Equal("tenant_1", result.Schemas[0].Schema)
+		suite.Equal(Failed, result.Schemas[0].State)
+		suite.Equal(suite.expectedErr, errors.Cause(result.Schemas[0].Err))
+		suite.Equal("tenant_2", result.Schemas[1].Schema)
+		suite.Equal(Skipped, result.Schemas[1].State)
+	}
+
+	suite.True(suite.output.Contains("tenant_2 " + Skipped.ToANSIColoredString()))
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_MigratesRemainingSchemas_InCaseOfSchemaFailureAndContinueOnError() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+	}
+
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	f := file.FindByVersion(1494538407, files)
+
+	suite.expectSchemaMigration("tenant_1", migrations, *f, suite.expectedErr)
+	suite.expectSchemaMigration("tenant_2", migrations, *f, nil)
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		Schemas:         []string{"tenant_1", "tenant_2"},
+		ContinueOnError: true,
+	}
+
+	// Act
+	result, err := suite.instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.EqualError(err, "migrating 1 of 2 schema(s) failed")
+	if suite.NotNil(result) && suite.Len(result.Schemas, 2) {
+		suite.Equal(Failed, result.Schemas[0].State)
+		suite.Equal(Succeeded, result.Schemas[1].State)
+		suite.Len(result.Schemas[1].Migrations, 1)
+		suite.Len(result.Migrations, 1)
+	}
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_MigratesSchemasMatchingPattern_InCaseOfSchemaPattern() {
+	// Arrange
+	// The following versions are from ../testdata.
+	migrations := version.Migrations{
+		1494538273: {Version: 1494538273},
+		1494538317: {Version: 1494538317},
+	}
+
+	files, err := file.ListFiles(filepath.Join("..", "testdata"), direction.Up)
+	suite.Require().NoError(err)
+
+	f := file.FindByVersion(1494538407, files)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectSchemas", mock.AnythingOfType("*context.timerCtx"), "tenant_%").Return([]string{"tenant_1"}, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+	suite.expectSchemaMigration("tenant_1", migrations, *f, nil)
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		SchemaPattern:   "tenant_%",
+	}
+
+	// Act
+	result, err := suite.instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	if suite.NotNil(result) && suite.Len(result.Schemas, 1) {
+		suite.Equal("tenant_1", result.Schemas[0].Schema)
+		suite.Equal(Succeeded, result.Schemas[0].State)
+	}
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_ReturnsError_InCaseOfNoSchemasMatchingPattern() {
+	// Arrange
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectSchemas", mock.AnythingOfType("*context.timerCtx"), "tenant_%").Return(nil, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		Path:            filepath.Join("..", "testdata"),
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+		SchemaPattern:   "tenant_%",
+	}
+
+	// Act
+	result, err := suite.instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.EqualError(err, "listing schemas failed: no schemas match tenant_%")
+	suite.Nil(result)
+}
+
+// private
+
+func (suite *MigratorTestSuite) expectSchemaMigration(schema string, migrations version.Migrations, f file.File, migrateErr error) {
+	table := driver.Table{Schema: schema, SearchPath: true}
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", table).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(migrations, nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), f, direction.Up).Return(migrateErr).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+}
@@ -0,0 +1,58 @@
+package migrator
+
+import (
+	"time"
+
+	"github.com/mgutz/ansi"
+)
+
+// State represents a state of a migration version
+type State string
+
+const (
+	// Applied means the migration file exists and is recorded in the database.
+	Applied State = "applied"
+	// Pending means the migration file exists but is not recorded in the database.
+	Pending State = "pending"
+	// Orphaned means the migration is recorded in the database but has no file.
+	Orphaned State = "orphaned"
+	// Dirty means the migration failed half way and the database needs manual fixing.
+	Dirty State = "dirty"
+)
+
+// ToANSIColoredString returns the colored string representation of the state.
+func (s State) ToANSIColoredString() string {
+	switch s {
+	case Applied:
+		return ansi.Green + string(s) + ansi.Reset
+	case Pending:
+		return ansi.Yellow + string(s) + ansi.Reset
+	default:
+		return ansi.Red + string(s) + ansi.Reset
+	}
+}
+
+// Status represents a status of a migration version.
+// Repeatable migrations are pending when their file has changed since it was applied.
+type Status struct {
+	Version    int64
+	Base       string
+	Repeatable bool
+	State      State
+	AppliedAt  *time.Time
+}
+
+// Statuses represents a list of migration statuses
+type Statuses []Status
+
+// Count returns the number of migrations in a given state
+func (statuses Statuses) Count(state State) int {
+	result := 0
+	for _, s := range statuses {
+		if s.State == state {
+			result++
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,157 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/mgutz/ansi"
+	"github.com/wallester/migrate/printer"
+)
+
+// migrateTargets migrates every database of URLs with its own driver,
+// running at most Concurrency of them at the same time
+func (m *Migrator) migrateTargets(ctx context.Context, args Args) (*Result, error) {
+	if m.newDriver == nil {
+		return nil, errors.New("migrating multiple databases requires a driver factory")
+	}
+
+	concurrency := args.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	started := time.Now()
+	targets := make([]TargetResult, len(args.URLs))
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, u := range args.URLs {
+		wg.Add(1)
+
+		go func(i int, u string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			targets[i] = m.migrateTarget(ctx, targetName(i, u), u, args)
+		}(i, u)
+	}
+
+	wg.Wait()
+
+	result := &Result{
+		Targets:  targets,
+		Duration: time.Since(started),
+	}
+
+	var failed, pending int
+	for _, t := range targets {
+		result.Migrations = append(result.Migrations, t.Migrations...)
+
+		switch {
+		case t.State == Failed:
+			failed++
+		case errors.Cause(t.Err) == ErrPendingMigrations:
+			pending++
+		}
+	}
+
+	m.printTargetResults(targets)
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return result, errors.Annotate(ErrInterrupted, "migrating databases failed")
+	}
+
+	if failed > 0 {
+		return result, fmt.Errorf("migrating %d of %d database(s) failed", failed, len(targets))
+	}
+
+	if pending > 0 {
+		return result, ErrPendingMigrations
+	}
+
+	return result, nil
+}
+
+// migrateTarget migrates one database with a new driver and output prefixed with the target name
+func (m *Migrator) migrateTarget(ctx context.Context, name, u string, args Args) TargetResult {
+	target := &Migrator{
+		db:     m.newDriver(),
+		output: printer.NewPrefixed(m.output, name),
+		source: m.source,
+	}
+
+	targetArgs := args
+	targetArgs.URL = u
+	targetArgs.URLs = nil
+
+	started := time.Now()
+	r := TargetResult{Target: name}
+
+	if len(targetArgs.Schemas) > 0 || targetArgs.SchemaPattern != "" {
+		var result *Result
+		result, r.Err = target.migrateSchemas(ctx, targetArgs)
+		if result != nil {
+			r.Migrations, r.Schemas = result.Migrations, result.Schemas
+		}
+	} else {
+		r.Migrations, r.Err = target.migrate(ctx, targetArgs)
+		target.printSummary(r.Migrations, started, targetArgs, r.Err)
+	}
+
+	r.Duration = time.Since(started)
+	r.State = outcome(r.Migrations, r.Err)
+
+	return r
+}
+
+// targetName returns host and database of the URL without credentials
+func targetName(i int, s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return fmt.Sprintf("#%d", i+1)
+	}
+
+	return u.Host + u.Path
+}
+
+func (m *Migrator) printTargetResults(results []TargetResult) {
+	count := map[string]int{
+		string(Succeeded): 0,
+		string(Current):   0,
+		string(Failed):    0,
+	}
+
+	if !m.structured() {
+		m.output.Println(fmt.Sprintf("%sDatabase results:%s", ansi.Yellow, ansi.Reset))
+	}
+
+	for _, r := range results {
+		count[string(r.State)]++
+
+		e := printer.Event{
+			Type:     printer.TargetMigrated,
+			Target:   r.Target,
+			State:    string(r.State),
+			Count:    map[string]int{"migrations": len(r.Migrations)},
+			Duration: r.Duration.Seconds(),
+		}
+
+		message := "-"
+		if r.Err != nil && errors.Cause(r.Err) != ErrPendingMigrations {
+			e.Error = r.Err.Error()
+			message = e.Error
+		}
+
+		if !m.event(e) {
+			m.output.Println(r.Target, r.State.ToANSIColoredString(), len(r.Migrations), "migration(s)", message)
+		}
+	}
+
+	m.event(printer.Event{Type: printer.Summary, Count: count})
+}
@@ -0,0 +1,70 @@
+package migrator
+
+import (
+	"context"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/version"
+)
+
+func (suite *MigratorTestSuite) Test_MigrateContext_UsesTimeoutDirective_InCaseOfLongerFileTimeout() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+		"2_backfill.up.sql":     {Data: []byte("-- migrate:timeout 10m\nupdate t set id = 1;")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	deadlines := make(map[string]time.Duration)
+	suite.expectRun(version.Migrations{})
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), mock.AnythingOfType("file.File"), direction.Up).Run(func(args mock.Arguments) {
+		deadline, _ := args.Get(0).(context.Context).Deadline()
+		deadlines[args.Get(1).(file.File).Base] = time.Until(deadline)
+	}).Return(nil).Twice()
+
+	args := Args{
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	_, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	suite.InDelta(10*time.Second, deadlines["1_create_table.up.sql"], float64(time.Second))
+	suite.InDelta(10*time.Minute, deadlines["2_backfill.up.sql"], float64(time.Second))
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_ReturnsError_InCaseOfRunTimeout() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_backfill.up.sql": {Data: []byte("-- migrate:timeout 10m\nupdate t set id = 1;")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.expectRun(version.Migrations{})
+	suite.driverMock.On("Migrate", mock.Anything, mock.AnythingOfType("file.File"), direction.Up).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Return(context.DeadlineExceeded).Once()
+
+	args := Args{
+		URL:                "connectionurl",
+		Direction:          direction.Up,
+		TimeoutDuration:    10 * time.Second,
+		RunTimeoutDuration: 50 * time.Millisecond,
+	}
+
+	// Act
+	_, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.EqualError(err, "migrating failed: applying migration failed: 1_backfill.up.sql: run timed out after 50ms: context deadline exceeded")
+}
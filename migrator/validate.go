@@ -0,0 +1,135 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/printer"
+)
+
+// Validate prints structural problems of the migration files and, given a database URL,
+// pending migrations older than the latest applied migration
+func (m *Migrator) Validate(args Args) ([]file.Problem, error) {
+	problems, err := m.listProblems(args)
+	if err != nil {
+		return nil, errors.Annotate(err, "validating migration files failed")
+	}
+
+	// Migration files cannot be listed reliably while there are blocking problems
+	if args.URL != "" && !hasBlockingProblems(problems) {
+		outOfOrder, err := m.outOfOrderProblems(context.Background(), args)
+		if err != nil {
+			return nil, err
+		}
+
+		problems = append(problems, outOfOrder...)
+	}
+
+	for _, p := range problems {
+		m.printProblem(p, args)
+	}
+
+	m.event(printer.Event{Type: printer.Summary, Count: map[string]int{"problems": len(problems)}})
+
+	return problems, nil
+}
+
+// private
+
+func (m *Migrator) listProblems(args Args) ([]file.Problem, error) {
+	if m.source != nil {
+		return m.source.Validate()
+	}
+
+	return file.NewDir(args.Path).Validate()
+}
+
+// validateFiles returns an error listing the problems of the migration files that make migrating unsafe
+func (m *Migrator) validateFiles(args Args) error {
+	problems, err := m.listProblems(args)
+	if err != nil {
+		return errors.Annotate(err, "validating migration files failed")
+	}
+
+	var blocking []string
+	for _, p := range problems {
+		if p.Blocking() {
+			blocking = append(blocking, p.String())
+		}
+	}
+
+	if len(blocking) > 0 {
+		return errors.Errorf("migration files are invalid, run validate for details: %s", strings.Join(blocking, "; "))
+	}
+
+	return nil
+}
+
+// outOfOrderProblems returns pending migrations older than the latest applied migration
+func (m *Migrator) outOfOrderProblems(ctx context.Context, args Args) ([]file.Problem, error) {
+	files, err := m.listFiles(args, direction.Up)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing migration files failed")
+	}
+
+	if err := m.open(ctx, args); err != nil {
+		return nil, err
+	}
+
+	defer m.close()
+
+	ctx, cancel := context.WithTimeout(ctx, args.TimeoutDuration)
+	defer cancel()
+
+	migrations, _, err := m.readMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []file.Problem
+	maxMigratedVersion := migrations.Versions().Max()
+	for _, f := range files {
+		if _, ok := migrations[f.Version]; !ok && f.Version < maxMigratedVersion {
+			problems = append(problems, file.Problem{
+				File:    f.Base,
+				Kind:    file.OutOfOrderProblem,
+				Message: fmt.Sprintf("pending version %d is older than the latest applied version %d", f.Version, maxMigratedVersion),
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+// printProblem prints the problem with the path of its file
+func (m *Migrator) printProblem(p file.Problem, args Args) {
+	if m.source == nil {
+		p.File = filepath.Join(args.Path, p.File)
+	}
+
+	if a, ok := m.output.(printer.IAnnotationPrinter); ok {
+		a.Annotate(printer.Annotation{File: p.File, Title: "migrate validate: " + p.Kind, Message: p.Message})
+		return
+	}
+
+	if m.event(printer.Event{Type: printer.ProblemFound, File: p.File, Rule: p.Kind, Message: p.Message}) {
+		return
+	}
+
+	m.output.Println(p.String())
+}
+
+func hasBlockingProblems(problems []file.Problem) bool {
+	for _, p := range problems {
+		if p.Blocking() {
+			return true
+		}
+	}
+
+	return false
+}
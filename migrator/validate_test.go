@@ -0,0 +1,159 @@
+package migrator
+
+import (
+	"context"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wallester/migrate/direction"
+	"github.com/wallester/migrate/driver"
+	"github.com/wallester/migrate/file"
+	"github.com/wallester/migrate/version"
+)
+
+func (suite *MigratorTestSuite) Test_Validate_ReturnsProblems_InCaseOfInvalidFiles() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql":   {Data: []byte("create table t(id int);")},
+		"1_create_table.down.sql": {Data: []byte("drop table t;")},
+		"2_add_column.up.sql":     {Data: []byte("")},
+		"3_add_index.UP.sql":      {Data: []byte("create index on t(id);")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	// Act
+	problems, err := instance.Validate(Args{})
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(problems, 3)
+	suite.Equal("2_add_column.up.sql: empty: file is empty\n"+
+		"2_add_column.up.sql: unpaired: down migration of version 2 is missing\n"+
+		"3_add_index.UP.sql: unrecognised: expected a lower case .up.sql or .down.sql suffix",
+		suite.output.String())
+}
+
+func (suite *MigratorTestSuite) Test_Validate_ReturnsOutOfOrderProblem_InCaseOfURL() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql":   {Data: []byte("create table t(id int);")},
+		"1_create_table.down.sql": {Data: []byte("drop table t;")},
+		"2_add_column.up.sql":     {Data: []byte("alter table t add c int;")},
+		"2_add_column.down.sql":   {Data: []byte("alter table t drop c;")},
+		"3_add_index.up.sql":      {Data: []byte("create index on t(id);")},
+		"3_add_index.down.sql":    {Data: []byte("drop index t_id_idx;")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(version.Migrations{
+		1: {Version: 1},
+		3: {Version: 3},
+	}, nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:                         "connectionurl",
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+	}
+
+	// Act
+	problems, err := instance.Validate(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Equal([]file.Problem{
+		{File: "2_add_column.up.sql", Kind: file.OutOfOrderProblem, Message: "pending version 2 is older than the latest applied version 3"},
+	}, problems)
+	suite.driverMock.AssertExpectations(suite.T())
+}
+
+func (suite *MigratorTestSuite) Test_Validate_ReturnsNoProblems_InCaseOfURLWithoutMigrationsTable() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql":   {Data: []byte("create table t(id int);")},
+		"1_create_table.down.sql": {Data: []byte("drop table t;")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(nil, driver.ErrNoMigrationsTable).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:                         "connectionurl",
+		TimeoutDuration:             time.Second,
+		DBConnectionTimeoutDuration: time.Second,
+	}
+
+	// Act
+	problems, err := instance.Validate(args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Empty(problems)
+	suite.driverMock.AssertNotCalled(suite.T(), "CreateMigrationsTable", mock.Anything)
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_ReturnsError_InCaseOfDuplicateVersions() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+		"1_create_view.up.sql":  {Data: []byte("create view v as select 1;")},
+	}
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	args := Args{
+		URL:       "connectionurl",
+		Direction: direction.Up,
+	}
+
+	// Act
+	result, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.EqualError(err, "migration files are invalid, run validate for details: "+
+		"1_create_view.up.sql: duplicate: version 1 is also used by 1_create_table.up.sql")
+	suite.Nil(result)
+	suite.driverMock.AssertExpectations(suite.T())
+}
+
+func (suite *MigratorTestSuite) Test_MigrateContext_Migrates_InCaseOfStraySQLFile() {
+	// Arrange
+	fsys := fstest.MapFS{
+		"1_create_table.up.sql": {Data: []byte("create table t(id int);")},
+		"structure.sql":         {Data: []byte("create table t(id int);")},
+	}
+
+	upFiles, err := file.ListFilesFS(fsys, direction.Up)
+	suite.Require().NoError(err)
+
+	instance := NewWithFS(suite.driverMock, suite.output, fsys)
+
+	suite.driverMock.On("Open", mock.AnythingOfType("*context.timerCtx"), "connectionurl", driver.Table{}).Return(nil).Once()
+	suite.driverMock.On("Lock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("Unlock", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("CreateMigrationsTable", mock.AnythingOfType("*context.timerCtx")).Return(nil).Once()
+	suite.driverMock.On("SelectMigrations", mock.AnythingOfType("*context.timerCtx")).Return(version.Migrations{}, nil).Once()
+	suite.driverMock.On("Migrate", mock.AnythingOfType("*context.timerCtx"), upFiles[0], direction.Up).Return(nil).Once()
+	suite.driverMock.On("Close").Return(nil).Once()
+
+	args := Args{
+		URL:             "connectionurl",
+		Direction:       direction.Up,
+		TimeoutDuration: 10 * time.Second,
+	}
+
+	// Act
+	result, err := instance.MigrateContext(context.Background(), args)
+
+	// Assert
+	suite.NoError(err)
+	suite.Len(result.Migrations, 1)
+}
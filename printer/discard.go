@@ -0,0 +1,9 @@
+package printer
+
+// Discard discards printer output
+type Discard struct{}
+
+var _ IPrinter = (*Discard)(nil)
+
+// Println does nothing
+func (d *Discard) Println(a ...interface{}) {}
@@ -0,0 +1,79 @@
+package printer
+
+import (
+	"time"
+)
+
+// EventType represents a type of structured output event
+type EventType string
+
+const (
+	// Started is printed before a migration file is executed.
+	Started EventType = "started"
+	// Finished is printed after a migration file has been executed.
+	Finished EventType = "finished"
+	// Failed is printed when executing a migration file failed.
+	Failed EventType = "failed"
+	// Planned is printed for migration files that a dry run would execute.
+	Planned EventType = "planned"
+	// Retrying is printed before a failed migration file is attempted again.
+	Retrying EventType = "retry"
+	// HookExecuted is printed after a before or after all hook has been executed.
+	HookExecuted EventType = "hook"
+	// Linted is printed for every finding of the lint command.
+	Linted EventType = "lint"
+	// ProblemFound is printed for every problem found by the validate command, with its kind as rule.
+	ProblemFound EventType = "problem"
+	// StatusReported is printed for every migration version by the status command.
+	StatusReported EventType = "status"
+	// Repaired is printed for every migration whose checksum was updated.
+	Repaired EventType = "repaired"
+	// Forced is printed when a version was marked as applied or not applied.
+	Forced EventType = "forced"
+	// Created is printed for every created migration file.
+	Created EventType = "created"
+	// Script is printed with the SQL script written to standard output.
+	Script EventType = "script"
+	// HistoryReported is printed for every recorded migration by the history command.
+	HistoryReported EventType = "history"
+	// TargetMigrated is printed for every database in the per database result.
+	TargetMigrated EventType = "target"
+	// SchemaMigrated is printed for every schema in the per schema result.
+	SchemaMigrated EventType = "schema"
+	// Message is printed for free text output.
+	Message EventType = "message"
+	// Summary is printed once at the end of a command.
+	Summary EventType = "summary"
+)
+
+// Event represents a structured output record
+type Event struct {
+	Time      time.Time      `json:"time"`
+	Type      EventType      `json:"event"`
+	Target    string         `json:"target,omitempty"`
+	Schema    string         `json:"schema,omitempty"`
+	File      string         `json:"file,omitempty"`
+	Version   int64          `json:"version,omitempty"`
+	Line      int            `json:"line,omitempty"`
+	Rule      string         `json:"rule,omitempty"`
+	Direction string         `json:"direction,omitempty"`
+	State     string         `json:"state,omitempty"`
+	AppliedAt *time.Time     `json:"applied_at,omitempty"`
+	StartedAt *time.Time     `json:"started_at,omitempty"`
+	Duration  float64        `json:"duration,omitempty"`
+	Attempt   int            `json:"attempt,omitempty"`
+	Checksum  string         `json:"checksum,omitempty"`
+	User      string         `json:"user,omitempty"`
+	Host      string         `json:"host,omitempty"`
+	Tool      string         `json:"tool_version,omitempty"`
+	SQL       string         `json:"sql,omitempty"`
+	Count     map[string]int `json:"count,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// IEventPrinter prints structured events in place of the text output
+type IEventPrinter interface {
+	IPrinter
+	Event(e Event)
+}
@@ -0,0 +1,77 @@
+package printer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Annotation represents a message about a line of a file, Line is 0 for the whole file
+type Annotation struct {
+	File    string
+	Line    int
+	Title   string
+	Message string
+}
+
+// IAnnotationPrinter prints annotations in place of the text output
+type IAnnotationPrinter interface {
+	IPrinter
+	Annotate(a Annotation)
+}
+
+// GitHub prints text and annotations as GitHub Actions workflow commands
+type GitHub struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var _ IAnnotationPrinter = (*GitHub)(nil)
+
+// NewGitHub returns new instance that writes to w
+func NewGitHub(w io.Writer) *GitHub {
+	return &GitHub{
+		w: w,
+	}
+}
+
+// Println prints the values as text
+func (p *GitHub) Println(a ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, _ = fmt.Fprintln(p.w, a...)
+}
+
+// Annotate prints the annotation as a warning workflow command
+func (p *GitHub) Annotate(a Annotation) {
+	properties := "file=" + escapeProperty(a.File)
+	if a.Line > 0 {
+		properties += fmt.Sprintf(",line=%d", a.Line)
+	}
+
+	if a.Title != "" {
+		properties += ",title=" + escapeProperty(a.Title)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, _ = fmt.Fprintf(p.w, "::warning %s::%s\n", properties, escapeData(a.Message))
+}
+
+// private
+
+var (
+	dataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func escapeData(s string) string {
+	return dataEscaper.Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return propertyEscaper.Replace(s)
+}
@@ -0,0 +1,24 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GitHub_PrintsWorkflowCommand_InCaseOfAnnotation(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	p := NewGitHub(&buf)
+
+	// Act
+	p.Annotate(Annotation{File: "db/1_a.up.sql", Line: 3, Title: "migrate lint: drop-table", Message: "50% done\nreally"})
+	p.Annotate(Annotation{File: "db/1_a,b.up.sql", Message: "whole file"})
+	p.Println("found", 2, "warnings")
+
+	// Assert
+	assert.Equal(t, "::warning file=db/1_a.up.sql,line=3,title=migrate lint%3A drop-table::50%25 done%0Areally\n"+
+		"::warning file=db/1_a%2Cb.up.sql::whole file\n"+
+		"found 2 warnings\n", buf.String())
+}
@@ -0,0 +1,52 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSON prints one JSON object per line
+type JSON struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+var _ IEventPrinter = (*JSON)(nil)
+
+// NewJSON returns new instance that writes to w
+func NewJSON(w io.Writer) *JSON {
+	return &JSON{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Println prints the values as a message event without ANSI colors
+func (p *JSON) Println(a ...interface{}) {
+	message := strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+	p.Event(Event{
+		Type:    Message,
+		Message: ansiPattern.ReplaceAllString(message, ""),
+	})
+}
+
+// Event prints the event
+func (p *JSON) Event(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	//nolint:errchkjson
+	_ = p.encoder.Encode(e)
+}
+
+// private
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
@@ -0,0 +1,49 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mgutz/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JSON_Println_PrintsMessageEventWithoutColors(t *testing.T) {
+	// Arrange
+	var b bytes.Buffer
+	p := NewJSON(&b)
+
+	// Act
+	p.Println(ansi.Green+">"+ansi.Reset, "1494538273_create_users.up.sql")
+
+	// Assert
+	assert.Regexp(t, `^\{"time":"[^"]+","event":"message","message":"\\u003e 1494538273_create_users.up.sql"\}\n$`, b.String())
+}
+
+func Test_JSON_Event_PrintsOneLinePerEvent(t *testing.T) {
+	// Arrange
+	var b bytes.Buffer
+	p := NewJSON(&b)
+	at := time.Date(2017, 5, 11, 21, 31, 13, 0, time.UTC)
+
+	// Act
+	p.Event(Event{Time: at, Type: Finished, File: "1494538273_create_users.up.sql", Version: 1494538273, Direction: "up", Duration: 0.5})
+	p.Event(Event{Time: at, Type: Summary, Count: map[string]int{"up": 1}, Error: "failure"})
+
+	// Assert
+	assert.Equal(t,
+		`{"time":"2017-05-11T21:31:13Z","event":"finished","file":"1494538273_create_users.up.sql","version":1494538273,"direction":"up","duration":0.5}`+"\n"+
+			`{"time":"2017-05-11T21:31:13Z","event":"summary","count":{"up":1},"error":"failure"}`+"\n",
+		b.String(),
+	)
+}
+
+func Test_NewWithFormat_ReturnsError_InCaseOfUnknownFormat(t *testing.T) {
+	// Act
+	p, err := NewWithFormat("xml")
+
+	// Assert
+	assert.EqualError(t, err, `unknown output format "xml"`)
+	assert.Nil(t, p)
+}
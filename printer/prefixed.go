@@ -0,0 +1,45 @@
+package printer
+
+// Prefixed prefixes printed lines with a target name
+type Prefixed struct {
+	output IPrinter
+	target string
+}
+
+var _ IPrinter = (*Prefixed)(nil)
+
+// PrefixedEvents prefixes printed lines and sets the target of printed events
+type PrefixedEvents struct {
+	Prefixed
+	events IEventPrinter
+}
+
+var _ IEventPrinter = (*PrefixedEvents)(nil)
+
+// NewPrefixed returns new instance that prints to output, keeping support for structured events
+func NewPrefixed(output IPrinter, target string) IPrinter {
+	prefixed := Prefixed{
+		output: output,
+		target: target,
+	}
+
+	if events, ok := output.(IEventPrinter); ok {
+		return &PrefixedEvents{
+			Prefixed: prefixed,
+			events:   events,
+		}
+	}
+
+	return &prefixed
+}
+
+// Println prints the values after the target name
+func (p *Prefixed) Println(a ...interface{}) {
+	p.output.Println(append([]interface{}{"[" + p.target + "]"}, a...)...)
+}
+
+// Event prints the event with its target set
+func (p *PrefixedEvents) Event(e Event) {
+	e.Target = p.target
+	p.events.Event(e)
+}
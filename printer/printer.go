@@ -2,6 +2,7 @@ package printer
 
 import (
 	"fmt"
+	"os"
 )
 
 // Printer prints
@@ -22,3 +23,26 @@ func (p *Printer) Println(a ...interface{}) {
 	//nolint:forbidigo
 	fmt.Println(a...)
 }
+
+const (
+	// FormatText prints human readable text.
+	FormatText = "text"
+	// FormatJSON prints one JSON event per line.
+	FormatJSON = "json"
+	// FormatGitHub prints text and lint findings as GitHub Actions annotations.
+	FormatGitHub = "github"
+)
+
+// NewWithFormat returns new instance that prints in the given format
+func NewWithFormat(format string) (IPrinter, error) {
+	switch format {
+	case "", FormatText:
+		return New(), nil
+	case FormatJSON:
+		return NewJSON(os.Stdout), nil
+	case FormatGitHub:
+		return NewGitHub(os.Stdout), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
@@ -3,10 +3,12 @@ package printer
 import (
 	"fmt"
 	"strings"
+	"sync"
 )
 
 // Recorder records printer output
 type Recorder struct {
+	mu     sync.Mutex
 	Output []string
 }
 
@@ -19,11 +21,17 @@ func (r *Recorder) Println(a ...interface{}) {
 		line = append(line, fmt.Sprintf("%v", v))
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.Output = append(r.Output, strings.Join(line, " "))
 }
 
 // String returns output as string
 func (r *Recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	return strings.Join(r.Output, "\n")
 }
 
@@ -0,0 +1,23 @@
+package version
+
+import (
+	"time"
+)
+
+// Tool is the version of migrate, it is recorded in the migration history
+var Tool = "1.0.2"
+
+// HistoryEntry represents an up or down migration recorded in the history table
+type HistoryEntry struct {
+	ID             int64
+	Version        int64
+	Direction      string
+	File           string
+	Checksum       string
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	Duration       time.Duration
+	DBUser         string
+	ClientHostname string
+	ToolVersion    string
+}
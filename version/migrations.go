@@ -0,0 +1,40 @@
+package version
+
+import (
+	"time"
+)
+
+// Migration represents a migration stored in the database, Name is set for repeatable migrations
+type Migration struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	Checksum  string
+	Dirty     bool
+}
+
+// Migrations represents a set of migrations stored in the database
+type Migrations map[int64]Migration
+
+// Versions returns versions of the migrations
+func (migrations Migrations) Versions() Versions {
+	var exists struct{}
+	result := make(Versions, len(migrations))
+	for v := range migrations {
+		result[v] = exists
+	}
+
+	return result
+}
+
+// Dirty returns the lowest dirty version and true if any migration is dirty
+func (migrations Migrations) Dirty() (int64, bool) {
+	result, found := int64(0), false
+	for v, migration := range migrations {
+		if migration.Dirty && (!found || v < result) {
+			result, found = v, true
+		}
+	}
+
+	return result, found
+}